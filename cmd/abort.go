@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// abortCmd 中止列表中的分段上传
+// abortCmd aborts the multipart uploads in a list
+var abortCmd = &cobra.Command{
+	Use:   "abort --from uploads.csv|-",
+	Short: "中止列表中指定的分段上传 | Abort exactly the multipart uploads in a list",
+	Long: `从 CSV 或 JSON Lines 读取 bucket、key、uploadId 并只中止这些上传，不扫描桶，也不按年龄、规则或过滤器判断。
+CSV 可以带表头，--target uploads --fmt=csv 的输出可以直接使用；列表中的行全部中止，加 --staleOnly 时跳过 ShouldDelete 为 false 的行，
+DeleteSuccess 为 true 的行已经中止过，总是被跳过；与主命令相同，默认只列出，加 --doDelete 才会中止，结果按 --fmt 输出
+Read bucket, key and uploadId from CSV or JSON Lines and abort exactly those uploads, without scanning buckets
+or judging by age, rules or filters. The CSV may have a header, the output of --target uploads --fmt=csv can be used
+directly; every row of the list is aborted, --staleOnly skips the rows whose ShouldDelete is false, and the rows whose
+DeleteSuccess is true were already aborted and are always skipped; like the main command it only lists by default,
+--doDelete performs the aborts, and the result is written in --fmt
+
+使用示例 | Usage examples:
+  # 保存报告，人工检查后中止其中的上传
+  # Save a report, review it and abort its uploads
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner --target=uploads --fmt=csv > uploads.csv
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner abort --from=uploads.csv --staleOnly --doDelete
+
+  # 从标准输入读取 JSON Lines
+  # Read JSON Lines from standard input
+  echo '{"bucket":"my-bucket","key":"a.bin","uploadId":"abc"}' | AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner abort --from=- --doDelete
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		uploads, err := readUploadList(cfg.AbortFrom, cfg.AbortStaleOnly)
+		if err != nil {
+			return err
+		}
+
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+		result, err := r.cleaner.AbortUploads(cmd.Context(), uploads, cfg.DoDelete)
+		if err != nil {
+			return err
+		}
+		return r.report(cmd.Context(), result)
+	},
+}
+
+// readUploadList 读取上传列表文件，- 表示标准输入
+// readUploadList reads the upload list file, - means standard input
+func readUploadList(path string, staleOnly bool) ([]cleaner.FileInfo, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("无法打开上传列表文件: %v\nFailed to open upload list file: %v", err, err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	uploads, err := render.ReadUploadList(in, staleOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return uploads, nil
+}
+
+func init() {
+	abortCmd.Flags().StringVar(&cfg.AbortFrom, "from", "", "要中止的上传列表文件（CSV 或 JSON Lines），- 表示标准输入 | Upload list file to abort (CSV or JSON Lines), - means standard input")
+	abortCmd.MarkFlagRequired("from")
+	abortCmd.Flags().BoolVar(&cfg.AbortStaleOnly, "staleOnly", false, "跳过列表中 ShouldDelete 为 false 的行 | Skip the rows of the list whose ShouldDelete is false")
+
+	rootCmd.AddCommand(abortCmd)
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/daemon"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// daemonCmd 以守护进程模式按计划运行清理
+// daemonCmd runs the cleaner on a schedule in daemon mode
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "以守护进程模式按计划运行清理 | Run the cleaner on a schedule in daemon mode",
+	Long: `以守护进程模式按计划运行清理，并通过HTTP提供 /healthz、/metrics 和 /last-report
+Run the cleaner on a schedule in daemon mode, serving /healthz, /metrics and /last-report over HTTP
+
+使用示例 | Usage examples:
+  # 每天凌晨3点清理7天前的临时文件
+  # Clean temporary files older than 7 days every day at 3 AM
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner daemon --schedule "0 3 * * *" --doDelete
+
+  # 每6小时列出一次临时文件
+  # List temporary files every 6 hours
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner daemon --interval 6h
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// 创建清理器 | Create cleaner
+		r, err := newRunner()
+		if err != nil {
+			return err
+		}
+
+		// 守护进程的日志输出到 stderr | Daemon logs go to stderr
+		logger, err := render.NewLogger(cfg.LogLevel, cfg.LogFormat, os.Stderr)
+		if err != nil {
+			return err
+		}
+
+		// 创建守护进程 | Create daemon
+		d, err := daemon.New(r.run, daemon.Options{
+			Schedule:   cfg.Schedule,
+			Interval:   cfg.Interval,
+			Jitter:     cfg.Jitter,
+			Listen:     cfg.Listen,
+			RunOnStart: cfg.RunOnStart,
+			Logger:     logger,
+		})
+		if err != nil {
+			return err
+		}
+
+		// 收到中断信号时退出 | Exit on interrupt signal
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return d.Run(ctx)
+	},
+}
+
+func init() {
+	daemonCmd.Flags().StringVar(&cfg.Schedule, "schedule", "", "cron 调度表达式，如 '0 3 * * *' | Cron schedule expression, e.g. '0 3 * * *'")
+	daemonCmd.Flags().DurationVar(&cfg.Interval, "interval", 0, "固定运行间隔，如 '6h'，与 --schedule 二选一 | Fixed run interval, e.g. '6h', mutually exclusive with --schedule")
+	daemonCmd.Flags().DurationVar(&cfg.Jitter, "jitter", time.Minute, "每次运行前随机延迟的最大时长 | Maximum random delay before each run")
+	daemonCmd.Flags().StringVar(&cfg.Listen, "listen", ":9102", "HTTP 服务监听地址 | HTTP server listen address")
+	daemonCmd.Flags().BoolVar(&cfg.RunOnStart, "runOnStart", false, "启动时立即运行一次 | Run once immediately on start")
+
+	rootCmd.AddCommand(daemonCmd)
+}
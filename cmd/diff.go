@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// diffCmd 比较两份保存的 JSON 报告
+// diffCmd compares two saved JSON reports
+var diffCmd = &cobra.Command{
+	Use:   "diff old.json new.json",
+	Short: "比较两份 JSON 报告中的过期文件 | Compare the stale files of two JSON reports",
+	Long: `比较两份 --fmt=json 保存的报告，按桶列出新出现的过期文件、期间已完成或已中止的文件，以及仍然存在的文件及其增长
+Compare two reports saved with --fmt=json, listing per bucket the newly stale files, the files completed or aborted in between,
+and the files still stuck together with their growth
+
+使用示例 | Usage examples:
+  # 每天只列出，保存报告
+  # List only every day and save the report
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner --fmt=json > report-$(date +%F).json
+
+  # 比较两天的报告
+  # Compare the reports of two days
+  s4-cleaner diff report-2025-01-01.json report-2025-01-02.json
+`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		oldReport, err := readReport(args[0])
+		if err != nil {
+			return err
+		}
+		newReport, err := readReport(args[1])
+		if err != nil {
+			return err
+		}
+
+		location, err := cfg.Location()
+		if err != nil {
+			return err
+		}
+		return render.Diff(os.Stdout, cleaner.Diff(oldReport, newReport), render.Options{Format: cfg.Format, Location: location, SizeUnits: cfg.SizeUnits})
+	},
+}
+
+// readReport 读取 JSON 报告文件
+// readReport reads a JSON report file
+func readReport(path string) (*cleaner.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开报告文件: %v\nFailed to open report file: %v", err, err)
+	}
+	defer file.Close()
+
+	report, err := render.ReadJSONReport(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
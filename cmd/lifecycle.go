@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+// lifecycleCmd 管理桶的 AbortIncompleteMultipartUpload lifecycle 规则
+// lifecycleCmd manages the AbortIncompleteMultipartUpload lifecycle rules of buckets
+var lifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "管理清理未完成分段上传的 lifecycle 规则 | Manage lifecycle rules that abort incomplete multipart uploads",
+	Long: `管理桶的 AbortIncompleteMultipartUpload lifecycle 规则，修改时保留桶中已有的其他规则
+修改规则时必须用 --bucket 或 --bucketsFrom 指定桶（所有桶使用 --bucket='*'），--dry-run 预览时不需要
+Manage the AbortIncompleteMultipartUpload lifecycle rules of buckets, keeping other existing rules when modifying.
+Changing rules requires buckets given with --bucket or --bucketsFrom (--bucket='*' for all buckets), previewing with --dry-run does not
+
+使用示例 | Usage examples:
+  # 列出所有桶的规则
+  # List the rules of all buckets
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner lifecycle show
+
+  # 预览为 uploads/ 前缀设置3天规则的修改
+  # Preview setting a 3-day rule for the uploads/ prefix
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner lifecycle set --bucket=my-bucket --prefix=uploads/ --olderThan=3d --dry-run
+
+  # 删除整个桶的规则
+  # Remove the bucket-wide rule
+  AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner lifecycle remove --bucket=my-bucket
+`,
+}
+
+// lifecycleShowCmd 列出 lifecycle 规则
+// lifecycleShowCmd lists lifecycle rules
+var lifecycleShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "列出每个桶的 AbortIncompleteMultipartUpload 规则 | List the AbortIncompleteMultipartUpload rules of each bucket",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s3Cleaner, err := newCleaner()
+		if err != nil {
+			return err
+		}
+		// 先输出能读取的规则，再报告失败的桶 | Write the readable rules first, then report the failed buckets
+		infos, bucketErrors, err := s3Cleaner.LifecycleRules(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if err := render.LifecycleRules(os.Stdout, infos, cfg.Format); err != nil {
+			return err
+		}
+		return bucketsFailed(bucketErrors)
+	},
+}
+
+// lifecycleSetCmd 添加或更新 lifecycle 规则
+// lifecycleSetCmd adds or updates the lifecycle rule
+var lifecycleSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "根据 --olderThan 和 --prefix 添加或更新规则 | Add or update the rule from --olderThan and --prefix",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireBuckets(); err != nil {
+			return err
+		}
+		s3Cleaner, err := newCleaner()
+		if err != nil {
+			return err
+		}
+		days, err := cfg.OlderThanDays()
+		if err != nil {
+			return err
+		}
+		changes, err := s3Cleaner.SetLifecycle(cmd.Context(), cfg.Prefix, days, cfg.DryRun)
+		if err != nil {
+			return err
+		}
+		render.LifecycleChanges(os.Stdout, changes)
+		return changesFailed(changes)
+	},
+}
+
+// lifecycleRemoveCmd 删除 lifecycle 规则
+// lifecycleRemoveCmd removes the lifecycle rule
+var lifecycleRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "删除 --prefix 对应的规则 | Remove the rule of --prefix",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireBuckets(); err != nil {
+			return err
+		}
+		s3Cleaner, err := newCleaner()
+		if err != nil {
+			return err
+		}
+		changes, err := s3Cleaner.RemoveLifecycle(cmd.Context(), cfg.Prefix, cfg.DryRun)
+		if err != nil {
+			return err
+		}
+		render.LifecycleChanges(os.Stdout, changes)
+		return changesFailed(changes)
+	},
+}
+
+// requireBuckets 修改规则前要求明确指定桶，避免默认修改所有桶；--dry-run 只预览，不需要指定
+// requireBuckets requires explicitly given buckets before changing rules, so all buckets are never changed by default;
+// --dry-run only previews and needs none
+func requireBuckets() error {
+	if cfg.DryRun || len(cfg.Buckets) > 0 || cfg.BucketsFrom != "" {
+		return nil
+	}
+	return fmt.Errorf("修改 lifecycle 规则需要用 --bucket 或 --bucketsFrom 指定桶，所有桶使用 --bucket='*'，也可以先用 --dry-run 预览\nChanging lifecycle rules requires buckets given with --bucket or --bucketsFrom, use --bucket='*' for all buckets or preview with --dry-run first")
+}
+
+// changesFailed 返回规则修改失败的桶组成的错误，全部成功时返回 nil
+// changesFailed returns an error listing the buckets whose rule change failed, or nil if all succeeded
+func changesFailed(changes []cleaner.LifecycleChange) error {
+	var bucketErrors []cleaner.BucketError
+	for _, change := range changes {
+		if change.Error != "" {
+			bucketErrors = append(bucketErrors, cleaner.BucketError{Bucket: change.Bucket, Error: change.Error})
+		}
+	}
+	return bucketsFailed(bucketErrors)
+}
+
+// bucketsFailed 返回列出失败的桶的错误，详细错误已在输出和日志中，没有失败时返回 nil
+// bucketsFailed returns an error listing the failed buckets, whose details are already in the output and logs, or nil if none failed
+func bucketsFailed(bucketErrors []cleaner.BucketError) error {
+	if len(bucketErrors) == 0 {
+		return nil
+	}
+	buckets := make([]string, 0, len(bucketErrors))
+	for _, bucketError := range bucketErrors {
+		buckets = append(buckets, bucketError.Bucket)
+	}
+	list := strings.Join(buckets, ", ")
+	return fmt.Errorf("%d 个桶处理失败: %s\n%d bucket(s) failed: %s", len(buckets), list, len(buckets), list)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{lifecycleSetCmd, lifecycleRemoveCmd} {
+		c.Flags().StringVar(&cfg.Prefix, "prefix", "", "规则的前缀过滤，为空表示整个桶 | Prefix filter of the rule, empty means the whole bucket")
+		c.Flags().BoolVar(&cfg.DryRun, "dry-run", false, "只显示修改差异，不实际执行 | Only show the diff without applying it")
+	}
+
+	lifecycleCmd.AddCommand(lifecycleShowCmd, lifecycleSetCmd, lifecycleRemoveCmd)
+	rootCmd.AddCommand(lifecycleCmd)
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/config"
+	"github.com/bitiful/s4-cleaner/pkg/notify"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+)
+
+// newCleaner 根据环境变量和命令行配置创建清理器
+// newCleaner creates a cleaner from environment variables and command line configuration
+func newCleaner() (*cleaner.S3Cleaner, error) {
+	// 检查必要的环境变量 | Check required environment variables
+	accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
+	secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
+
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("必须设置环境变量 AWS_ACCESS_KEY_ID 和 AWS_SECRET_ACCESS_KEY\nEnvironment variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	}
+
+	opts, err := cleanerOptions()
+	if err != nil {
+		return nil, err
+	}
+	opts.AccessKey = accessKey
+	opts.SecretKey = secretKey
+
+	return cleaner.New(opts)
+}
+
+// cleanerOptions 将命令行配置转换为清理器选项
+// cleanerOptions converts the command line configuration to cleaner options
+func cleanerOptions() (cleaner.Options, error) {
+	olderThan, err := cfg.OlderThan()
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	buckets, err := cfg.BucketList()
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	rules, err := policyRules()
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	maxDeleteBytes, err := cfg.DeleteBytesLimit()
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	// 在 stderr 上输出进度 | Report progress on stderr
+	progress, err := render.NewProgress(cfg.Progress, cfg.SizeUnits, os.Stderr)
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	// 在 stderr 上输出日志，不打断进度行 | Write logs to stderr without breaking the progress line
+	logger, err := render.NewLogger(cfg.LogLevel, cfg.LogFormat, progress.Writer(os.Stderr))
+	if err != nil {
+		return cleaner.Options{}, err
+	}
+
+	// 配置文件中的价格覆盖默认价格 | Prices in the config file override the defaults
+	pricing := cleaner.DefaultPricing().WithOverrides(cfg.Pricing.Classes)
+	if cfg.Pricing.Currency != "" {
+		pricing.Currency = cfg.Pricing.Currency
+	}
+	if cfg.Pricing.Default != nil {
+		pricing.Default = *cfg.Pricing.Default
+	}
+
+	return cleaner.Options{
+		Buckets:           buckets,
+		ExcludeBuckets:    cfg.ExcludeBuckets,
+		OlderThan:         olderThan,
+		AgeBasis:          cfg.AgeBasis,
+		Rules:             rules,
+		Where:             cfg.Where,
+		Initiators:        cfg.Initiators,
+		ExcludeInitiators: cfg.ExcludeInitiators,
+		Owners:            cfg.Owners,
+		ExcludeOwners:     cfg.ExcludeOwners,
+		Target:            cfg.Target,
+		Presets:           cfg.Presets,
+		Patterns:          cfg.Patterns,
+		KeepVersions:      cfg.KeepVersions,
+		SortBy:            cfg.SortBy,
+		Reverse:           cfg.Reverse,
+		MaxDeleteCount:    cfg.MaxDeleteCount,
+		MaxDeleteBytes:    maxDeleteBytes,
+		GroupBy:           cfg.GroupBy,
+		Pricing:           &pricing,
+		Checkpoint:        cfg.Checkpoint,
+		MaxRetries:        cfg.MaxRetries,
+		RetryMode:         cfg.RetryMode,
+		RequestTimeout:    cfg.RequestTimeout,
+		ConnectTimeout:    cfg.ConnectTimeout,
+		Logger:            logger,
+		OnProgress:        progress.Hook(),
+	}, nil
+}
+
+// policyRules 读取 --policy 指定的策略文件并转换为清理器规则
+// policyRules reads the policy file given by --policy and converts it to cleaner rules
+func policyRules() ([]cleaner.Rule, error) {
+	if cfg.Policy == "" {
+		return nil, nil
+	}
+	policy, err := config.LoadPolicy(cfg.Policy)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]cleaner.Rule, 0, len(policy))
+	for _, rule := range policy {
+		rules = append(rules, cleaner.Rule{
+			Name:      rule.Name,
+			Bucket:    rule.Bucket,
+			Prefix:    rule.Prefix,
+			Regex:     rule.Regex,
+			MinSize:   rule.MinSize,
+			MaxSize:   rule.MaxSize,
+			Initiator: rule.Initiator,
+			Action:    rule.Action,
+			OlderThan: rule.OlderThan,
+		})
+	}
+	return rules, nil
+}
+
+// runner 运行清理器并输出结果、导出指标、发送通知和邮件报告
+// runner runs the cleaner and writes the result, exports metrics, and sends notifications and email reports
+type runner struct {
+	cleaner  *cleaner.S3Cleaner
+	notifier *notify.Notifier
+	mailer   *notify.Mailer
+	output   render.Options
+}
+
+// newRunner 根据命令行配置创建清理器、通知器和邮件发送器
+// newRunner creates the cleaner, notifier and mailer from the command line configuration
+func newRunner() (*runner, error) {
+	if cfg.GroupsFile != "" && cfg.GroupBy == "" {
+		return nil, fmt.Errorf("--groupsFile 需要同时指定 --groupBy\n--groupsFile requires --groupBy")
+	}
+
+	s3Cleaner, err := newCleaner()
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, err := notify.New(cfg.Notify, cfg.NotifyTemplate, cfg.NotifySkipEmpty, cfg.SizeUnits)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := cfg.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	// 邮件报告包含全部文件，只使用相同的时区和容量单位 | The email report has all files and only shares the time zone and size units
+	mailer, err := notify.NewMailer(cfg.SMTP, cfg.MailTo, render.Options{Location: location, SizeUnits: cfg.SizeUnits})
+	if err != nil {
+		return nil, err
+	}
+
+	output := render.Options{Format: cfg.Format, Top: cfg.Top, Location: location, SizeUnits: cfg.SizeUnits}
+	return &runner{cleaner: s3Cleaner, notifier: notifier, mailer: mailer, output: output}, nil
+}
+
+// run 运行一次清理，输出结果、导出指标、发送通知和邮件报告
+// run runs the cleaner once, writes the result, exports metrics, and sends notifications and the email report
+func (r *runner) run(ctx context.Context) (*cleaner.Result, error) {
+	run := r.cleaner.Scan
+	if cfg.DoDelete {
+		run = r.cleaner.Clean
+	}
+
+	result, err := run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result, r.report(ctx, result)
+}
+
+// report 输出结果、导出指标、发送通知和邮件报告
+// report writes the result, exports metrics, and sends notifications and the email report
+func (r *runner) report(ctx context.Context, result *cleaner.Result) error {
+	// 根据格式输出结果，单个桶的错误已记录在日志中 | Output results based on format, errors of individual buckets are already logged
+	if err := render.Result(os.Stdout, result, r.output); err != nil {
+		return err
+	}
+
+	// 写入分组汇总、导出指标、发送通知和邮件，其中一项失败时仍然执行其他项
+	// Write the group summary, export metrics, notify and mail, still doing the others if one fails
+	groupsErr := render.WriteGroupsFile(cfg.GroupsFile, result, r.output)
+	metricsErr := render.ExportMetrics(result, cfg.MetricsFile, cfg.Pushgateway)
+	notifyErr := r.notifier.Notify(ctx, result, !cfg.DoDelete)
+	mailErr := r.mailer.Send(ctx, result, !cfg.DoDelete)
+	return errors.Join(groupsErr, metricsErr, notifyErr, mailErr)
+}
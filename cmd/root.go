@@ -11,9 +11,10 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/bitiful/s4-cleaner/pkg/cleaner"
 	"github.com/bitiful/s4-cleaner/pkg/config"
+	"github.com/bitiful/s4-cleaner/pkg/render"
 	"github.com/spf13/cobra"
 )
 
@@ -53,22 +54,15 @@ S3 Temporary File Cleaner - Clean up expired temporary files in S3 buckets
   AWS_ACCESS_KEY_ID=your_ak AWS_SECRET_ACCESS_KEY=your_sk s4-cleaner --fmt=json
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// 检查必要的环境变量 | Check required environment variables
-		accessKey := os.Getenv("AWS_ACCESS_KEY_ID")
-		secretKey := os.Getenv("AWS_SECRET_ACCESS_KEY")
-
-		if accessKey == "" || secretKey == "" {
-			return fmt.Errorf("必须设置环境变量 AWS_ACCESS_KEY_ID 和 AWS_SECRET_ACCESS_KEY\nEnvironment variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
-		}
-
 		// 创建清理器 | Create cleaner
-		s3Cleaner, err := cleaner.NewS3Cleaner(accessKey, secretKey, cfg)
+		r, err := newRunner()
 		if err != nil {
 			return err
 		}
 
 		// 执行清理操作 | Execute cleaning operation
-		return s3Cleaner.Run()
+		_, err = r.run(cmd.Context())
+		return err
 	},
 }
 
@@ -80,10 +74,47 @@ func Execute() error {
 
 func init() {
 	// 初始化标志 | Initialize flags
-	rootCmd.PersistentFlags().StringVar(&cfg.Bucket, "bucket", "", "存储桶名称，为空表示所有桶 | Bucket name, empty means all buckets")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Buckets, "bucket", nil, "存储桶名称或 glob 模式（如 'logs-*'），可重复指定，为空表示所有桶 | Bucket name or glob pattern (e.g. 'logs-*'), may be repeated, empty means all buckets")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.ExcludeBuckets, "excludeBucket", nil, "排除的存储桶名称或 glob 模式，可重复指定 | Bucket name or glob pattern to exclude, may be repeated")
+	rootCmd.PersistentFlags().StringVar(&cfg.BucketsFrom, "bucketsFrom", "", "从文件读取存储桶名称或 glob 模式，每行一个，- 表示标准输入 | Read bucket names or glob patterns from a file, one per line, - means standard input")
 	rootCmd.PersistentFlags().StringVar(&cfg.Time, "olderThan", "7d", "查找早于此时间的文件，如 '7d'（7天前）或 '72h'（72小时前） | Find files older than this time, e.g. '7d' (7 days ago) or '72h' (72 hours ago)")
+	rootCmd.PersistentFlags().StringVar(&cfg.AgeBasis, "ageBasis", "initiated", "uploads 模式下判断年龄的依据：initiated（发起时间）, lastPart（最新分段的上传时间） | What the age of multipart uploads is judged by: initiated (initiation time), lastPart (upload time of the newest part)")
 	rootCmd.PersistentFlags().BoolVar(&cfg.DoDelete, "doDelete", false, "是否执行删除操作，默认为false（仅列出） | Whether to perform deletion, default is false (list only)")
-	rootCmd.PersistentFlags().StringVar(&cfg.Format, "fmt", "table", "输出格式：table, json, csv | Output format: table, json, csv")
+	rootCmd.PersistentFlags().StringVar(&cfg.Format, "fmt", "table", "输出格式："+strings.Join(render.Formats(), ", ")+" | Output format: "+strings.Join(render.Formats(), ", "))
+	rootCmd.PersistentFlags().StringVar(&cfg.SizeUnits, "sizeUnits", render.SizeUnitsIEC, "输出容量的单位：iec（KiB, MiB）, si（kB, MB）, bytes | Units of the output sizes: iec (KiB, MiB), si (kB, MB), bytes")
+	rootCmd.PersistentFlags().StringVar(&cfg.TimeZone, "tz", "local", "输出时间使用的时区：local, UTC 或 IANA 时区名称如 Asia/Shanghai | Time zone of the output times: local, UTC or an IANA zone name such as Asia/Shanghai")
+	rootCmd.PersistentFlags().StringVar(&cfg.Target, "target", "uploads", "清理目标：uploads（未完成的分段上传）, objects（匹配临时文件模式的对象）, versions（非当前版本和孤立的删除标记） | Cleaning target: uploads (incomplete multipart uploads), objects (objects matching temporary file patterns), versions (noncurrent versions and orphaned delete markers)")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Presets, "preset", nil, "objects 模式的临时文件预设：spark, s3a, tmp, part, rclone，默认全部 | Temporary file presets in objects mode: spark, s3a, tmp, part, rclone, default all")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Patterns, "pattern", nil, "objects 模式的自定义临时文件模式，如 '*.bak' 或 'staging/' | Custom temporary file patterns in objects mode, e.g. '*.bak' or 'staging/'")
+	rootCmd.PersistentFlags().IntVar(&cfg.KeepVersions, "keepVersions", 0, "versions 模式下每个对象保留的最新非当前版本数 | Number of newest noncurrent versions to keep per object in versions mode")
+	rootCmd.PersistentFlags().StringVar(&cfg.SortBy, "sortBy", "", "排序方式，也决定删除顺序：size, age, key, bucket | Sort order, also decides the deletion order: size, age, key, bucket")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Reverse, "reverse", false, "反转排序 | Reverse the sort order")
+	rootCmd.PersistentFlags().IntVar(&cfg.Top, "top", 0, "只输出前N条记录，0 表示全部 | Only output the first N rows, 0 means all")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxDeleteCount, "maxDeleteCount", 0, "一次运行最多删除的文件数，0 表示不限制 | Maximum number of files to delete in one run, 0 means unlimited")
+	rootCmd.PersistentFlags().StringVar(&cfg.MaxDeleteBytes, "maxDeleteBytes", "", "一次运行最多删除的容量，如 '100GiB' | Maximum size to delete in one run, e.g. '100GiB'")
+	rootCmd.PersistentFlags().StringVar(&cfg.GroupBy, "groupBy", "", "分组汇总方式：bucket, prefix:N, initiator, ageBucket | Group summary by: bucket, prefix:N, initiator, ageBucket")
+	rootCmd.PersistentFlags().StringVar(&cfg.GroupsFile, "groupsFile", "", "另外以CSV格式写入分组汇总的文件，需要 --groupBy | Also write the group summary to this file in CSV format, requires --groupBy")
+	rootCmd.PersistentFlags().StringVar(&cfg.Checkpoint, "checkpoint", "", "记录扫描进度的检查点文件，中断后再次运行时从此处继续 | Checkpoint file recording the scan progress, so an interrupted run resumes from there")
+	rootCmd.PersistentFlags().StringVar(&cfg.Progress, "progress", "auto", "stderr 上的进度输出方式：auto（终端时输出进度行）, line, json, none | Progress mode on stderr: auto (a progress line on a terminal), line, json, none")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogLevel, "logLevel", "warn", "stderr 上的日志级别：debug, info, warn, error | Log level on stderr: debug, info, warn, error")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogFormat, "logFormat", "text", "日志格式：text, json | Log format: text, json")
+	rootCmd.PersistentFlags().StringVar(&cfg.MetricsFile, "metricsFile", "", "以 node_exporter textfile 格式写入指标的文件 | Write metrics to this file in node_exporter textfile format")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxRetries, "maxRetries", 3, "每个S3请求的最大重试次数 | Maximum number of retries of each S3 request")
+	rootCmd.PersistentFlags().StringVar(&cfg.RetryMode, "retryMode", "standard", "重试模式：standard, adaptive | Retry mode: standard, adaptive")
+	rootCmd.PersistentFlags().DurationVar(&cfg.RequestTimeout, "requestTimeout", 2*time.Minute, "每次S3请求尝试的超时时间，0 表示不限制 | Timeout of each S3 request attempt, 0 means no timeout")
+	rootCmd.PersistentFlags().DurationVar(&cfg.ConnectTimeout, "connectTimeout", 10*time.Second, "建立连接的超时时间，0 表示不限制 | Timeout of establishing a connection, 0 means no timeout")
+	rootCmd.PersistentFlags().StringVar(&cfg.Pushgateway, "pushgateway", "", "推送指标的 Prometheus Pushgateway 地址 | Prometheus Pushgateway URL to push metrics to")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Notify, "notify", nil, "运行后发送摘要的通知目标，格式为 kind=url，kind 为 webhook, slack, dingtalk, feishu, wecom | Notification targets receiving the run summary, formatted as kind=url where kind is webhook, slack, dingtalk, feishu, wecom")
+	rootCmd.PersistentFlags().StringVar(&cfg.NotifyTemplate, "notifyTemplate", "", "通知消息的 text/template 模板文件 | text/template file of the notification message")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.MailTo, "mailTo", nil, "每次运行后接收邮件报告的地址，SMTP 设置在配置文件中 | Addresses receiving the email report after each run, with SMTP settings in the config file")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Initiators, "initiator", nil, "uploads 模式下只处理这些发起者的上传，按名称或ID匹配，可重复指定 | Only process uploads of these initiators in uploads mode, matched by display name or ID, may be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.ExcludeInitiators, "excludeInitiator", nil, "uploads 模式下跳过这些发起者的上传，按名称或ID匹配，可重复指定 | Skip uploads of these initiators in uploads mode, matched by display name or ID, may be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.Owners, "owner", nil, "uploads 模式下只处理这些所有者的上传，按名称或ID匹配，可重复指定 | Only process uploads of these owners in uploads mode, matched by display name or ID, may be repeated")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.ExcludeOwners, "excludeOwner", nil, "uploads 模式下跳过这些所有者的上传，按名称或ID匹配，可重复指定 | Skip uploads of these owners in uploads mode, matched by display name or ID, may be repeated")
+	rootCmd.PersistentFlags().StringVar(&cfg.Where, "where", "", "过滤表达式，如 'size > 1GiB && age > 3d && key matches \"^tmp/\"' | Filter expression, e.g. 'size > 1GiB && age > 3d && key matches \"^tmp/\"'")
+	rootCmd.PersistentFlags().StringVar(&cfg.Policy, "policy", "", "YAML 策略文件，按顺序匹配的规则决定每个文件保留还是按各自的时长清理 | YAML policy file whose ordered rules decide whether each file is kept or cleaned after its own duration")
+	rootCmd.PersistentFlags().StringVar(&cfg.ConfigFile, "config", "", "YAML 配置文件，包含 SMTP 和价格设置 | YAML config file, which holds the SMTP and pricing settings")
+	rootCmd.PersistentFlags().BoolVar(&cfg.NotifySkipEmpty, "notifySkipEmpty", false, "没有发现过期文件且没有失败的桶时不发送通知 | Skip the notification when no stale files were found and no bucket failed")
 
 	// 添加版本标志 | Add version flag
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "显示版本信息 | Show version information")
@@ -95,10 +126,26 @@ func init() {
 		}
 
 		// 验证格式标志 | Validate format flag
-		validFormats := map[string]bool{"table": true, "json": true, "csv": true}
-		if !validFormats[strings.ToLower(cfg.Format)] {
-			fmt.Fprintf(os.Stderr, "错误：无效的格式 '%s'，有效选项为: table, json, csv\nError: Invalid format '%s', valid options are: table, json, csv\n", cfg.Format, cfg.Format)
+		if _, err := render.Lookup(cfg.Format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// 验证容量单位标志 | Validate size units flag
+		if err := render.CheckSizeUnits(cfg.SizeUnits); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+
+		// 读取配置文件 | Load config file
+		if cfg.ConfigFile != "" {
+			file, err := config.LoadFile(cfg.ConfigFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			cfg.SMTP = file.SMTP
+			cfg.Pricing = file.Pricing
+		}
 	}
 }
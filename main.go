@@ -11,6 +11,10 @@ import (
 	"fmt"
 	"os"
 
+	// 内置时区数据库，使 --tz 在 Windows 和没有 zoneinfo 的容器中也能使用
+	// Embed the time zone database so that --tz also works on Windows and in containers without zoneinfo
+	_ "time/tzdata"
+
 	"github.com/bitiful/s4-cleaner/cmd"
 )
 
@@ -0,0 +1,72 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"time"
+)
+
+// AbortUploads 中止列表中的分段上传，不扫描桶，也不按年龄、规则或过滤器判断；doDelete 为 false 时只报告。
+// 列表中的每一项需要 Bucket、Key 和 UploadID，删除预算仍然生效
+// AbortUploads aborts the multipart uploads in the list without scanning buckets or judging by age, rules or filters;
+// it only reports when doDelete is false. Every entry needs Bucket, Key and UploadID, and the delete budget still applies
+func (c *S3Cleaner) AbortUploads(ctx context.Context, uploads []FileInfo, doDelete bool) (*Result, error) {
+	retriesBefore := c.retries.Load()
+	result := &Result{
+		Target:        TargetUploads,
+		GroupBy:       c.opts.GroupBy,
+		Pricing:       c.pricing,
+		StartTime:     time.Now(),
+		Buckets:       []string{},
+		FailedBuckets: []string{},
+		Files:         []FileInfo{},
+	}
+	r := &run{
+		start:    result.StartTime,
+		doDelete: doDelete,
+		budget:   newDeleteBudget(c.opts),
+	}
+
+	// 按桶分组，保持每个桶第一次出现的顺序
+	// Group by bucket, keeping the order in which each bucket first appears
+	byBucket := map[string][]FileInfo{}
+	for _, upload := range uploads {
+		if _, ok := byBucket[upload.Bucket]; !ok {
+			result.Buckets = append(result.Buckets, upload.Bucket)
+		}
+		byBucket[upload.Bucket] = append(byBucket[upload.Bucket], FileInfo{
+			Bucket:       upload.Bucket,
+			Key:          upload.Key,
+			Size:         upload.Size,
+			ModTime:      upload.ModTime,
+			Type:         FileTypeUpload,
+			UploadID:     upload.UploadID,
+			ShouldDelete: true,
+		})
+	}
+
+	c.log.Info("开始中止列表中的上传 | Aborting listed uploads", "uploads", len(uploads), "buckets", len(result.Buckets), "delete", doDelete)
+	r.progress = newProgress(c.opts.OnProgress, len(result.Buckets))
+	defer r.progress.finish()
+
+	for _, bucket := range result.Buckets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		files := byBucket[bucket]
+		r.progress.startBucket(bucket)
+		r.progress.addPage(files)
+		c.deleteFiles(ctx, r, files)
+		r.progress.finishBucket()
+		result.Files = append(result.Files, files...)
+	}
+
+	c.finishResult(result, retriesBefore)
+	return result, nil
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// checkpointMarkers 继续列出一个桶所需的分页标记
+// checkpointMarkers are the pagination markers needed to continue listing a bucket
+type checkpointMarkers struct {
+	// KeyMarker uploads 和 versions 模式下的键标记
+	// Key marker in uploads and versions mode
+	KeyMarker string `json:"key_marker,omitempty"`
+
+	// UploadIDMarker uploads 模式下的上传ID标记
+	// Upload ID marker in uploads mode
+	UploadIDMarker string `json:"upload_id_marker,omitempty"`
+
+	// StartAfter objects 模式下从此键之后开始列出
+	// Start listing after this key in objects mode
+	StartAfter string `json:"start_after,omitempty"`
+}
+
+// checkpointSettings 影响扫描和删除结果的选项，只有选项相同时才能从检查点继续；Filter 回调无法比较，不在其中
+// checkpointSettings are the options affecting what is scanned and deleted, a checkpoint can only be resumed with the same options;
+// the Filter callback cannot be compared and is not included
+type checkpointSettings struct {
+	Target            string        `json:"target"`
+	DoDelete          bool          `json:"do_delete"`
+	OlderThan         time.Duration `json:"older_than"`
+	Buckets           []string      `json:"buckets"`
+	ExcludeBuckets    []string      `json:"exclude_buckets"`
+	AgeBasis          string        `json:"age_basis,omitempty"`
+	Rules             []Rule        `json:"rules,omitempty"`
+	Where             string        `json:"where,omitempty"`
+	Initiators        []string      `json:"initiators,omitempty"`
+	ExcludeInitiators []string      `json:"exclude_initiators,omitempty"`
+	Owners            []string      `json:"owners,omitempty"`
+	ExcludeOwners     []string      `json:"exclude_owners,omitempty"`
+	Presets           []string      `json:"presets,omitempty"`
+	Patterns          []string      `json:"patterns,omitempty"`
+	KeepVersions      int           `json:"keep_versions,omitempty"`
+	MaxDeleteCount    int           `json:"max_delete_count,omitempty"`
+	MaxDeleteBytes    int64         `json:"max_delete_bytes,omitempty"`
+}
+
+// newCheckpointSettings 从选项中取出影响扫描和删除结果的部分
+// newCheckpointSettings takes the options affecting what is scanned and deleted
+func newCheckpointSettings(opts Options, doDelete bool) checkpointSettings {
+	return checkpointSettings{
+		Target:            opts.Target,
+		DoDelete:          doDelete,
+		OlderThan:         opts.OlderThan,
+		Buckets:           opts.Buckets,
+		ExcludeBuckets:    opts.ExcludeBuckets,
+		AgeBasis:          opts.AgeBasis,
+		Rules:             opts.Rules,
+		Where:             opts.Where,
+		Initiators:        opts.Initiators,
+		ExcludeInitiators: opts.ExcludeInitiators,
+		Owners:            opts.Owners,
+		ExcludeOwners:     opts.ExcludeOwners,
+		Presets:           opts.Presets,
+		Patterns:          opts.Patterns,
+		KeepVersions:      opts.KeepVersions,
+		MaxDeleteCount:    opts.MaxDeleteCount,
+		MaxDeleteBytes:    opts.MaxDeleteBytes,
+	}
+}
+
+// checkpoint 记录扫描进度的检查点，nil 表示不使用检查点
+// checkpoint records the scan progress, nil means checkpointing is disabled
+type checkpoint struct {
+	path string
+
+	checkpointSettings
+	Start       time.Time `json:"start"`
+	DoneBuckets []string  `json:"done_buckets"`
+	Bucket      string    `json:"bucket,omitempty"`
+	checkpointMarkers
+
+	// DeletedCount 和 DeletedBytes 已经消耗的删除预算，继续时不会重新计算
+	// The delete budget already spent, which is not reset on resume
+	DeletedCount int   `json:"deleted_count"`
+	DeletedBytes int64 `json:"deleted_bytes"`
+
+	// budget 本次运行的删除预算，保存时记录其消耗
+	// budget is the delete budget of this run, whose spending is recorded on save
+	budget *deleteBudget
+
+	// held 有文件因超出删除预算而跳过的桶，不再推进其分页标记
+	// held is the bucket where files were skipped over the delete budget, whose markers no longer advance
+	held string
+}
+
+// loadCheckpoint 读取检查点文件，文件不存在时以 start 为开始时间从头开始；path 为空时返回 nil。
+// 继续时沿用检查点记录的开始时间，使截止时间与中断的运行一致
+// loadCheckpoint reads the checkpoint file, starting from scratch at start if it does not exist; returns nil if path is empty.
+// A resumed run keeps the start time recorded in the checkpoint, so its cutoff matches the interrupted run
+func loadCheckpoint(path string, settings checkpointSettings, start time.Time) (*checkpoint, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	cp := &checkpoint{path: path, checkpointSettings: settings, Start: start, DoneBuckets: []string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("无法读取检查点文件: %v\nFailed to read checkpoint file: %v", err, err)
+	}
+	saved := &checkpoint{path: path}
+	if err := json.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("无法解析检查点文件 %s: %v\nFailed to parse checkpoint file %s: %v", path, err, path, err)
+	}
+
+	// 不同清理目标的分页标记不能通用
+	// Pagination markers cannot be shared between cleaning targets
+	if saved.Target != settings.Target {
+		return nil, fmt.Errorf("检查点文件 %s 属于清理目标 '%s'，与当前目标 '%s' 不一致\nCheckpoint file %s belongs to target '%s', which differs from the current target '%s'", path, saved.Target, settings.Target, path, saved.Target, settings.Target)
+	}
+
+	// 其他选项不同时，已完成的桶和当前运行的判断不一致
+	// With other options changed, the finished buckets were judged differently from the current run
+	var changed []string
+	for _, option := range []struct {
+		name    string
+		changed bool
+	}{
+		{"--doDelete", saved.DoDelete != settings.DoDelete},
+		{"--olderThan", saved.OlderThan != settings.OlderThan},
+		{"--bucket", !slices.Equal(saved.Buckets, settings.Buckets)},
+		{"--excludeBucket", !slices.Equal(saved.ExcludeBuckets, settings.ExcludeBuckets)},
+		{"--ageBasis", saved.AgeBasis != settings.AgeBasis},
+		{"--policy", !slices.Equal(saved.Rules, settings.Rules)},
+		{"--where", saved.Where != settings.Where},
+		{"--initiator", !slices.Equal(saved.Initiators, settings.Initiators)},
+		{"--excludeInitiator", !slices.Equal(saved.ExcludeInitiators, settings.ExcludeInitiators)},
+		{"--owner", !slices.Equal(saved.Owners, settings.Owners)},
+		{"--excludeOwner", !slices.Equal(saved.ExcludeOwners, settings.ExcludeOwners)},
+		{"--preset", !slices.Equal(saved.Presets, settings.Presets)},
+		{"--pattern", !slices.Equal(saved.Patterns, settings.Patterns)},
+		{"--keepVersions", saved.KeepVersions != settings.KeepVersions},
+		{"--maxDeleteCount", saved.MaxDeleteCount != settings.MaxDeleteCount},
+		{"--maxDeleteBytes", saved.MaxDeleteBytes != settings.MaxDeleteBytes},
+	} {
+		if option.changed {
+			changed = append(changed, option.name)
+		}
+	}
+	if len(changed) > 0 {
+		options := strings.Join(changed, ", ")
+		return nil, fmt.Errorf("检查点文件 %s 记录的选项 %s 与当前运行不一致，请使用相同的选项或删除该检查点文件\nThe options %s recorded in checkpoint file %s differ from the current run, use the same options or remove the checkpoint file", path, options, options, path)
+	}
+	if saved.Start.IsZero() {
+		saved.Start = start
+	}
+	return saved, nil
+}
+
+// trackBudget 从检查点恢复已经消耗的删除预算，此后保存时记录预算的消耗
+// trackBudget restores the delete budget already spent from the checkpoint, and records the spending on every save
+func (cp *checkpoint) trackBudget(budget *deleteBudget) {
+	if cp == nil {
+		return
+	}
+	budget.count = cp.DeletedCount
+	budget.bytes = cp.DeletedBytes
+	cp.budget = budget
+}
+
+// done 检查桶是否已在之前的运行中处理完成
+// done checks whether the bucket was finished in a previous run
+func (cp *checkpoint) done(bucket string) bool {
+	return cp != nil && slices.Contains(cp.DoneBuckets, bucket)
+}
+
+// markers 返回继续列出桶所需的分页标记，没有记录时从头开始
+// markers returns the pagination markers to continue listing the bucket, starting from scratch if none were recorded
+func (cp *checkpoint) markers(bucket string) checkpointMarkers {
+	if cp == nil || cp.Bucket != bucket {
+		return checkpointMarkers{}
+	}
+	return cp.checkpointMarkers
+}
+
+// hold 记录桶中有文件因超出删除预算而跳过，此后不再推进该桶的分页标记，也不将其记为完成，
+// 使继续的运行重新列出这些文件
+// hold records that files in the bucket were skipped over the delete budget, so the markers of the bucket
+// no longer advance and it is not recorded as finished, and a resumed run lists those files again
+func (cp *checkpoint) hold(bucket string) {
+	if cp == nil {
+		return
+	}
+	cp.held = bucket
+}
+
+// savePage 记录当前桶中下一页的分页标记，应在当前页的删除完成后调用
+// savePage records the markers of the next page in the current bucket, and must be called after the page is deleted
+func (cp *checkpoint) savePage(bucket string, markers checkpointMarkers) error {
+	if cp == nil {
+		return nil
+	}
+	if cp.held != bucket {
+		cp.Bucket = bucket
+		cp.checkpointMarkers = markers
+	}
+	return cp.save()
+}
+
+// finishBucket 记录桶已处理完成，有文件因超出删除预算而跳过的桶只保存预算的消耗
+// finishBucket records that the bucket is finished, only saving the budget spent for a bucket with files
+// skipped over the delete budget
+func (cp *checkpoint) finishBucket(bucket string) error {
+	if cp == nil {
+		return nil
+	}
+	if cp.held == bucket {
+		cp.held = ""
+		return cp.save()
+	}
+	cp.DoneBuckets = append(cp.DoneBuckets, bucket)
+	cp.Bucket = ""
+	cp.checkpointMarkers = checkpointMarkers{}
+	return cp.save()
+}
+
+// remove 在整次运行完成后删除检查点文件
+// remove deletes the checkpoint file after the whole run is finished
+func (cp *checkpoint) remove() error {
+	if cp == nil {
+		return nil
+	}
+	if err := os.Remove(cp.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("无法删除检查点文件: %v\nFailed to remove checkpoint file: %v", err, err)
+	}
+	return nil
+}
+
+// save 先写入临时文件再重命名，避免中断时留下不完整的检查点
+// save writes to a temporary file and renames it, so an interruption never leaves a partial checkpoint
+func (cp *checkpoint) save() error {
+	if cp.budget != nil {
+		cp.DeletedCount = cp.budget.count
+		cp.DeletedBytes = cp.budget.bytes
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化检查点: %v\nFailed to serialize checkpoint: %v", err, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), filepath.Base(cp.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("无法创建检查点文件: %v\nFailed to create checkpoint file: %v", err, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("无法写入检查点文件: %v\nFailed to write checkpoint file: %v", err, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("无法写入检查点文件: %v\nFailed to write checkpoint file: %v", err, err)
+	}
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("无法写入检查点文件: %v\nFailed to write checkpoint file: %v", err, err)
+	}
+	return nil
+}
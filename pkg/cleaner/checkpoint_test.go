@@ -0,0 +1,188 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testCheckpointSettings = checkpointSettings{
+	Target:         TargetUploads,
+	DoDelete:       true,
+	OlderThan:      7 * 24 * time.Hour,
+	Buckets:        []string{"logs-*"},
+	ExcludeBuckets: []string{"logs-keep"},
+	Rules:          []Rule{{Prefix: "tmp/", Action: RuleActionAbort, OlderThan: 24 * time.Hour}},
+	MaxDeleteCount: 100,
+}
+
+func TestCheckpointDisabled(t *testing.T) {
+	cp, err := loadCheckpoint("", testCheckpointSettings, time.Now())
+	if cp != nil || err != nil {
+		t.Fatalf("loadCheckpoint(\"\") = %v, %v, want nil, nil", cp, err)
+	}
+	if cp.done("a") || cp.markers("a") != (checkpointMarkers{}) {
+		t.Error("nil checkpoint reports progress")
+	}
+	if err := cp.savePage("a", checkpointMarkers{KeyMarker: "k"}); err != nil {
+		t.Error(err)
+	}
+	if err := cp.finishBucket("a"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cp, err := loadCheckpoint(path, testCheckpointSettings, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.finishBucket("logs-1"); err != nil {
+		t.Fatal(err)
+	}
+	markers := checkpointMarkers{KeyMarker: "tmp/b.bin", UploadIDMarker: "u2"}
+	if err := cp.savePage("logs-2", markers); err != nil {
+		t.Fatal(err)
+	}
+
+	// 继续时沿用记录的进度和开始时间 | A resumed run keeps the recorded progress and start time
+	resumed, err := loadCheckpoint(path, testCheckpointSettings, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed.done("logs-1") || resumed.done("logs-2") {
+		t.Errorf("done buckets = %v, want [logs-1]", resumed.DoneBuckets)
+	}
+	if got := resumed.markers("logs-2"); got != markers {
+		t.Errorf("markers(logs-2) = %+v, want %+v", got, markers)
+	}
+	if got := resumed.markers("logs-3"); got != (checkpointMarkers{}) {
+		t.Errorf("markers(logs-3) = %+v, want none", got)
+	}
+	if !resumed.Start.Equal(start) {
+		t.Errorf("Start = %v, want %v", resumed.Start, start)
+	}
+
+	// 删除后从头开始 | After removal the next run starts from scratch
+	if err := resumed.remove(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("checkpoint file still exists: %v", err)
+	}
+	fresh, err := loadCheckpoint(path, testCheckpointSettings, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.done("logs-1") || !fresh.Start.Equal(start.Add(time.Hour)) {
+		t.Errorf("fresh checkpoint = %+v, want no progress", fresh)
+	}
+}
+
+func TestCheckpointSettingsChanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(s *checkpointSettings)
+		want   string
+	}{
+		{"target", func(s *checkpointSettings) { s.Target = TargetObjects }, "target 'uploads'"},
+		{"doDelete", func(s *checkpointSettings) { s.DoDelete = false }, "--doDelete"},
+		{"olderThan", func(s *checkpointSettings) { s.OlderThan = 24 * time.Hour }, "--olderThan"},
+		{"bucket", func(s *checkpointSettings) { s.Buckets = []string{"logs-1"} }, "--bucket"},
+		{"excludeBucket", func(s *checkpointSettings) { s.ExcludeBuckets = nil }, "--excludeBucket"},
+		{"ageBasis", func(s *checkpointSettings) { s.AgeBasis = AgeBasisLastPart }, "--ageBasis"},
+		{"policy", func(s *checkpointSettings) { s.Rules = []Rule{{Prefix: "tmp/", Action: RuleActionKeep}} }, "--policy"},
+		{"where", func(s *checkpointSettings) { s.Where = "size > 1GiB" }, "--where"},
+		{"initiator", func(s *checkpointSettings) { s.Initiators = []string{"alice"} }, "--initiator"},
+		{"excludeInitiator", func(s *checkpointSettings) { s.ExcludeInitiators = []string{"alice"} }, "--excludeInitiator"},
+		{"owner", func(s *checkpointSettings) { s.Owners = []string{"alice"} }, "--owner"},
+		{"excludeOwner", func(s *checkpointSettings) { s.ExcludeOwners = []string{"alice"} }, "--excludeOwner"},
+		{"preset", func(s *checkpointSettings) { s.Presets = []string{"office"} }, "--preset"},
+		{"pattern", func(s *checkpointSettings) { s.Patterns = []string{"*.tmp"} }, "--pattern"},
+		{"keepVersions", func(s *checkpointSettings) { s.KeepVersions = 3 }, "--keepVersions"},
+		{"maxDeleteCount", func(s *checkpointSettings) { s.MaxDeleteCount = 0 }, "--maxDeleteCount"},
+		{"maxDeleteBytes", func(s *checkpointSettings) { s.MaxDeleteBytes = 1 << 30 }, "--maxDeleteBytes"},
+		{"several", func(s *checkpointSettings) { s.DoDelete = false; s.Buckets = nil }, "--doDelete, --bucket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+			cp, err := loadCheckpoint(path, testCheckpointSettings, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cp.finishBucket("logs-1"); err != nil {
+				t.Fatal(err)
+			}
+
+			settings := testCheckpointSettings
+			tt.change(&settings)
+			_, err = loadCheckpoint(path, settings, time.Now())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("loadCheckpoint() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckpointBudget(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cp, err := loadCheckpoint(path, testCheckpointSettings, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget := &deleteBudget{maxCount: 3}
+	cp.trackBudget(budget)
+	budget.consume(100)
+	budget.consume(200)
+	if err := cp.finishBucket("logs-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 超出预算跳过文件后，分页标记停在跳过之前，桶也不记为完成
+	// After files are skipped over the budget, the markers stay before them and the bucket is not finished
+	before := checkpointMarkers{KeyMarker: "a.bin", UploadIDMarker: "u1"}
+	if err := cp.savePage("logs-2", before); err != nil {
+		t.Fatal(err)
+	}
+	budget.consume(50)
+	cp.hold("logs-2")
+	if err := cp.savePage("logs-2", checkpointMarkers{KeyMarker: "z.bin", UploadIDMarker: "u9"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.finishBucket("logs-2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 继续时恢复已经消耗的预算 | A resumed run restores the budget already spent
+	resumed, err := loadCheckpoint(path, testCheckpointSettings, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed.done("logs-1") || resumed.done("logs-2") {
+		t.Errorf("done buckets = %v, want [logs-1]", resumed.DoneBuckets)
+	}
+	if got := resumed.markers("logs-2"); got != before {
+		t.Errorf("markers(logs-2) = %+v, want %+v", got, before)
+	}
+	restored := &deleteBudget{maxCount: 3}
+	resumed.trackBudget(restored)
+	if restored.count != 3 || restored.bytes != 350 || restored.allow(1) {
+		t.Errorf("restored budget = %+v, want 3 deletions of 350 bytes with nothing left", restored)
+	}
+}
@@ -9,120 +9,505 @@ package cleaner
 
 import (
 	"context"
-	"encoding/csv"
-	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"path"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
-	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/bitiful/s4-cleaner/pkg/config"
-	"github.com/fatih/color"
-	"github.com/olekukonko/tablewriter"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
-// S3Cleaner S3清理器
-// S3Cleaner is a cleaner for S3 buckets
+// S3Cleaner S3清理器，可以被多个 goroutine 同时使用
+// S3Cleaner is a cleaner for S3 buckets, safe for concurrent use
 type S3Cleaner struct {
-	client *s3.Client
-	cfg    *config.Config
+	client   S3API
+	opts     Options
+	groupKey groupKeyFunc
+	compare  compareFunc
+	patterns []string
+	retries  *atomic.Int64
+	pricing  Pricing
+	rules    []compiledRule
+	where    whereFunc
+	log      *slog.Logger
 }
 
+// 清理目标
+// Cleaning targets
+const (
+	// TargetUploads 未完成的分段上传
+	// TargetUploads is incomplete multipart uploads
+	TargetUploads = "uploads"
+
+	// TargetObjects 匹配临时文件模式的已完成对象
+	// TargetObjects is completed objects matching temporary file patterns
+	TargetObjects = "objects"
+
+	// TargetVersions 非当前版本和孤立的删除标记
+	// TargetVersions is noncurrent versions and orphaned delete markers
+	TargetVersions = "versions"
+)
+
+// 分段上传的年龄依据
+// Age basis of multipart uploads
+const (
+	// AgeBasisInitiated 按上传的发起时间判断年龄
+	// AgeBasisInitiated judges the age by when the upload was initiated
+	AgeBasisInitiated = "initiated"
+
+	// AgeBasisLastPart 按最新上传的分段时间判断年龄，没有分段时使用发起时间
+	// AgeBasisLastPart judges the age by the newest uploaded part, falling back to the initiation time without parts
+	AgeBasisLastPart = "lastPart"
+)
+
+// 文件类型
+// File types
+const (
+	FileTypeUpload       = "upload"
+	FileTypeObject       = "object"
+	FileTypeVersion      = "version"
+	FileTypeDeleteMarker = "delete_marker"
+)
+
 // FileInfo 文件信息
 // FileInfo contains information about a file
 type FileInfo struct {
-	Bucket        string    `json:"bucket"`
-	Key           string    `json:"key"`
-	Size          int64     `json:"size"`
-	ModTime       time.Time `json:"mod_time"`
-	ShouldDelete  bool      `json:"should_delete"`
-	DeleteSuccess *bool     `json:"delete_success,omitempty"`
-}
-
-// NewS3Cleaner 创建新的S3清理器
-// NewS3Cleaner creates a new S3 cleaner
-func NewS3Cleaner(accessKey, secretKey string, cfg *config.Config) (*S3Cleaner, error) {
-	// 解析过期时间
-	// Parse expiration time
-	if err := cfg.ParseTime(); err != nil {
+	Bucket        string     `json:"bucket"`
+	Key           string     `json:"key"`
+	Size          int64      `json:"size"`
+	ModTime       time.Time  `json:"mod_time"`
+	LastPartTime  *time.Time `json:"last_part_time,omitempty"`
+	Type          string     `json:"type"`
+	Rule          string     `json:"rule,omitempty"`
+	StorageClass  string     `json:"storage_class,omitempty"`
+	Initiator     string     `json:"initiator,omitempty"`
+	InitiatorID   string     `json:"initiator_id,omitempty"`
+	Owner         string     `json:"owner,omitempty"`
+	UploadID      string     `json:"upload_id,omitempty"`
+	VersionID     string     `json:"version_id,omitempty"`
+	ShouldDelete  bool       `json:"should_delete"`
+	DeleteSuccess *bool      `json:"delete_success,omitempty"`
+	Skipped       bool       `json:"skipped,omitempty"`
+}
+
+// AgeTime 返回判断文件年龄的时间：有最新分段时间时使用它，否则使用修改时间
+// AgeTime returns the time the file's age is judged by: the newest part time if there is one, otherwise the mod time
+func (f FileInfo) AgeTime() time.Time {
+	if f.LastPartTime != nil {
+		return *f.LastPartTime
+	}
+	return f.ModTime
+}
+
+// New 根据选项创建新的S3清理器
+// New creates a new S3 cleaner from options
+func New(opts Options) (*S3Cleaner, error) {
+	if opts.OlderThan < 0 {
+		return nil, fmt.Errorf("无效的时间 %s，不能为负数\nInvalid duration %s, must not be negative", opts.OlderThan, opts.OlderThan)
+	}
+
+	// 解析清理目标，为空时使用 uploads
+	// Parse cleaning target, uploads when empty
+	if opts.Target == "" {
+		opts.Target = TargetUploads
+	}
+	var patterns []string
+	var err error
+	switch opts.Target {
+	case TargetUploads, TargetVersions:
+	case TargetObjects:
+		patterns, err = resolvePatterns(opts.Presets, opts.Patterns)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("无效的清理目标 '%s'，有效选项为: uploads, objects, versions\nInvalid target '%s', valid options are: uploads, objects, versions", opts.Target, opts.Target)
+	}
+
+	// 检查桶模式
+	// Check bucket patterns
+	if err := validateBucketPatterns(slices.Concat(opts.Buckets, opts.ExcludeBuckets)); err != nil {
 		return nil, err
 	}
 
-	// 创建AWS配置
-	// Create AWS configuration
-	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(),
-		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-		awsconfig.WithRegion("us-east-1"), // 默认区域，会根据桶自动调整 | Default region, will be adjusted automatically based on bucket
-	)
+	// 编译策略规则
+	// Compile policy rules
+	rules, err := compileRules(opts.Rules)
 	if err != nil {
-		return nil, fmt.Errorf("无法加载AWS配置: %v\nFailed to load AWS configuration: %v", err, err)
+		return nil, err
+	}
+
+	// 发起者和所有者只有分段上传才有
+	// Only multipart uploads have an initiator and an owner
+	if len(opts.Initiators)+len(opts.ExcludeInitiators)+len(opts.Owners)+len(opts.ExcludeOwners) > 0 && opts.Target != TargetUploads {
+		return nil, fmt.Errorf("--initiator 和 --owner 过滤只能用于 --target uploads\n--initiator and --owner filters can only be used with --target uploads")
+	}
+
+	// 编译过滤表达式
+	// Compile the filter expression
+	where, err := compileWhere(opts.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	// 年龄依据只对分段上传有意义
+	// The age basis only makes sense for multipart uploads
+	switch opts.AgeBasis {
+	case "", AgeBasisInitiated:
+	case AgeBasisLastPart:
+		if opts.Target != TargetUploads {
+			return nil, fmt.Errorf("--ageBasis lastPart 只能用于 --target uploads\n--ageBasis lastPart can only be used with --target uploads")
+		}
+	default:
+		return nil, fmt.Errorf("无效的年龄依据 '%s'，有效选项为: initiated, lastPart\nInvalid age basis '%s', valid options are: initiated, lastPart", opts.AgeBasis, opts.AgeBasis)
+	}
+
+	// 解析分组方式
+	// Parse group by
+	groupKey, err := parseGroupBy(opts.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析排序方式
+	// Parse sort order
+	compare, err := parseSortBy(opts.SortBy, opts.Reverse)
+	if err != nil {
+		return nil, err
+	}
+
+	// 排序时在扫描完所有桶后才删除，检查点无法保证已扫描的文件都已处理
+	// With sorting, deletion happens after all buckets are scanned, so a checkpoint cannot guarantee scanned files were handled
+	if opts.Checkpoint != "" && compare != nil {
+		return nil, fmt.Errorf("--checkpoint 不能与 --sortBy 同时使用\n--checkpoint cannot be combined with --sortBy")
+	}
+
+	// 未指定价格时使用默认价格
+	// Use the default prices when no pricing is given
+	pricing := DefaultPricing()
+	if opts.Pricing != nil {
+		pricing = *opts.Pricing
+	}
+
+	// 未指定日志记录器时丢弃日志
+	// Discard logs when no logger is given
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
 	}
 
 	// 创建S3客户端
 	// Create S3 client
-	client := s3.NewFromConfig(awsCfg)
+	retries := &atomic.Int64{}
+	client := opts.Client
+	if client == nil {
+		client, err = newS3Client(opts, retries, logger)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &S3Cleaner{
-		client: client,
-		cfg:    cfg,
+		client:   client,
+		opts:     opts,
+		groupKey: groupKey,
+		compare:  compare,
+		patterns: patterns,
+		retries:  retries,
+		pricing:  pricing,
+		rules:    rules,
+		where:    where,
+		log:      logger,
 	}, nil
 }
 
-// Run 执行清理操作
-// Run executes the cleaning operation
-func (c *S3Cleaner) Run() error {
-	var buckets []string
-	var err error
+// Result 一次运行的结果
+// Result is the outcome of one run
+type Result struct {
+	Target        string         `json:"target"`
+	GroupBy       string         `json:"group_by,omitempty"`
+	AgeBasis      string         `json:"age_basis,omitempty"`
+	StartTime     time.Time      `json:"start_time"`
+	EndTime       time.Time      `json:"end_time"`
+	Buckets       []string       `json:"buckets"`
+	FailedBuckets []string       `json:"failed_buckets"`
+	Errors        []BucketError  `json:"errors,omitempty"`
+	Files         []FileInfo     `json:"files"`
+	Rules         []string       `json:"rules,omitempty"`
+	Statistics    Statistics     `json:"statistics"`
+	Pricing       Pricing        `json:"-"`
+	Cost          Cost           `json:"cost"`
+	Groups        []GroupSummary `json:"groups,omitempty"`
+	Retries       int64          `json:"retries"`
+}
 
-	// 如果指定了桶，则只处理该桶
-	// If bucket is specified, only process that bucket
-	if c.cfg.Bucket != "" {
-		buckets = []string{c.cfg.Bucket}
-	} else {
-		// 否则获取所有桶
-		// Otherwise get all buckets
-		buckets, err = c.listBuckets()
+// BucketError 处理某个桶时发生的错误
+// BucketError is an error that occurred while processing a bucket
+type BucketError struct {
+	Bucket string `json:"bucket"`
+	Error  string `json:"error"`
+}
+
+// Duration 返回本次运行的耗时
+// Duration returns how long the run took
+func (r *Result) Duration() time.Duration {
+	return r.EndTime.Sub(r.StartTime)
+}
+
+// run 一次运行的状态
+// run is the state of one run
+type run struct {
+	start    time.Time
+	cutoff   time.Time
+	doDelete bool
+	budget   *deleteBudget
+	cp       *checkpoint
+	progress *progress
+}
+
+// Scan 扫描所有桶，只报告需要清理的文件，不做任何删除
+// Scan scans all buckets and reports the files to clean without deleting anything
+func (c *S3Cleaner) Scan(ctx context.Context) (*Result, error) {
+	return c.process(ctx, false)
+}
+
+// Clean 扫描所有桶，并在删除预算内删除需要清理的文件
+// Clean scans all buckets and deletes the files to clean within the delete budget
+func (c *S3Cleaner) Clean(ctx context.Context) (*Result, error) {
+	return c.process(ctx, true)
+}
+
+// process 扫描所有桶，doDelete 为 true 时删除需要清理的文件，返回本次运行的结果
+// process scans all buckets, deletes the files to clean if doDelete is true, and returns the result of this run
+func (c *S3Cleaner) process(ctx context.Context, doDelete bool) (*Result, error) {
+	retriesBefore := c.retries.Load()
+	result := &Result{
+		Target:        c.opts.Target,
+		GroupBy:       c.opts.GroupBy,
+		AgeBasis:      c.opts.AgeBasis,
+		Pricing:       c.pricing,
+		Rules:         c.ruleNames(),
+		StartTime:     time.Now(),
+		Buckets:       []string{},
+		FailedBuckets: []string{},
+		Files:         []FileInfo{},
+	}
+
+	// 每次运行的截止时间不同
+	// Each run has its own cutoff
+	r := &run{
+		start:    result.StartTime,
+		cutoff:   result.StartTime.Add(-c.opts.OlderThan),
+		doDelete: doDelete,
+		budget:   newDeleteBudget(c.opts),
+	}
+
+	buckets, err := c.targetBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 读取检查点，跳过之前的运行中已完成的桶，沿用中断的运行的截止时间，
+	// 并恢复已经消耗的删除预算
+	// Load the checkpoint to skip buckets finished in a previous run, keeping the cutoff of the interrupted run
+	// and restoring the delete budget already spent
+	r.cp, err = loadCheckpoint(c.opts.Checkpoint, newCheckpointSettings(c.opts, doDelete), r.start)
+	if err != nil {
+		return nil, err
+	}
+	if r.cp != nil {
+		r.start = r.cp.Start
+		r.cutoff = r.start.Add(-c.opts.OlderThan)
+	}
+	r.cp.trackBudget(r.budget)
+
+	c.log.Info("开始运行 | Run started", "target", c.opts.Target, "buckets", len(buckets), "delete", doDelete, "cutoff", r.cutoff)
+	r.progress = newProgress(c.opts.OnProgress, len(buckets))
+	defer r.progress.finish()
+
+	// 指定排序方式时，先扫描所有桶，再按排序顺序删除
+	// When a sort order is specified, scan all buckets first and then delete in sorted order
+	inline := r
+	if c.compare != nil {
+		inline = &run{start: r.start, cutoff: r.cutoff, cp: r.cp, progress: r.progress}
+	}
+
+	// 处理每个桶，收集所有文件信息
+	// Process each bucket and collect all file information
+	for _, bucket := range buckets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if r.cp.done(bucket) {
+			c.log.Info("检查点中已完成，跳过桶 | Bucket finished in checkpoint, skipping", "bucket", bucket)
+			r.progress.finishBucket()
+			continue
+		}
+		c.log.Info("开始处理桶 | Processing bucket", "bucket", bucket)
+		r.progress.startBucket(bucket)
+		bucketStart := time.Now()
+
+		var files []FileInfo
+		switch c.opts.Target {
+		case TargetObjects:
+			files, err = c.processObjectsInBucket(ctx, inline, bucket)
+		case TargetVersions:
+			files, err = c.processVersionsInBucket(ctx, inline, bucket)
+		default:
+			files, err = c.processOneBucket(ctx, inline, bucket)
+		}
+		if err == nil {
+			err = r.cp.finishBucket(bucket)
+		}
+		r.progress.finishBucket()
 		if err != nil {
-			return err
+			c.log.Error("处理桶失败 | Failed to process bucket", "bucket", bucket, "error", err)
+			result.FailedBuckets = append(result.FailedBuckets, bucket)
+			result.Errors = append(result.Errors, BucketError{Bucket: bucket, Error: err.Error()})
+			continue
 		}
+		stats := ComputeStatistics(files)
+		c.log.Info("桶处理完成 | Bucket finished", "bucket", bucket, "files", stats.TotalFiles, "to_delete", stats.FilesToDelete,
+			"deleted", stats.FilesDeleted, "failed", stats.FilesFailed, "duration", time.Since(bucketStart))
+		result.Buckets = append(result.Buckets, bucket)
+		result.Files = append(result.Files, files...)
 	}
 
-	// 收集所有文件信息
-	// Collect all file information
-	allFiles := []FileInfo{}
+	if c.compare != nil {
+		slices.SortStableFunc(result.Files, c.compare)
+		c.deleteFiles(ctx, r, result.Files)
+	}
 
-	// 处理每个桶
-	// Process each bucket
-	for _, bucket := range buckets {
-		files, err := c.processOneBucket(bucket)
+	// 所有桶都成功处理后删除检查点，否则下次运行只处理未完成的桶
+	// Remove the checkpoint once all buckets succeeded, otherwise the next run only processes unfinished buckets
+	if len(result.FailedBuckets) == 0 {
+		if err := r.cp.remove(); err != nil {
+			return nil, err
+		}
+	}
+
+	c.finishResult(result, retriesBefore)
+	return result, nil
+}
+
+// finishResult 在运行结束时填写结果的结束时间、重试次数、统计、成本和分组
+// finishResult fills in the end time, retries, statistics, cost and groups of the result when the run ends
+func (c *S3Cleaner) finishResult(result *Result, retriesBefore int64) {
+	result.EndTime = time.Now()
+	result.Retries = c.retries.Load() - retriesBefore
+	result.Statistics = ComputeStatistics(result.Files)
+	result.Cost = ComputeCost(result.Files, result.Pricing, result.StartTime)
+	if c.groupKey != nil {
+		result.Groups = groupFiles(result.Files, c.groupKey, result.StartTime)
+	}
+	c.log.Info("运行完成 | Run finished", "buckets", len(result.Buckets), "failed_buckets", len(result.FailedBuckets),
+		"files", result.Statistics.TotalFiles, "deleted", result.Statistics.FilesDeleted, "retries", result.Retries, "duration", result.Duration())
+}
+
+// keep 检查扫描到的文件是否通过过滤器和过滤表达式，过滤表达式中的年龄按本次运行的开始时间计算
+// keep checks whether a scanned file passes the filter and the filter expression, whose ages are computed
+// from the start of the run
+func (c *S3Cleaner) keep(r *run, file FileInfo) bool {
+	if c.where != nil && !c.where(file, r.start) {
+		return false
+	}
+	return c.opts.Filter == nil || c.opts.Filter(file)
+}
+
+// keepLogged 检查扫描到的文件是否通过过滤器，并在 debug 级别记录对该文件的判断
+// keepLogged checks whether a scanned file passes the filter and logs the decision for the file at debug level
+func (c *S3Cleaner) keepLogged(r *run, file FileInfo) bool {
+	kept := c.keep(r, file)
+	c.log.Debug("文件判断 | File decision", "bucket", file.Bucket, "key", file.Key, "type", file.Type,
+		"upload_id", file.UploadID, "version_id", file.VersionID, "mod_time", file.ModTime, "size", file.Size,
+		"should_delete", file.ShouldDelete, "filtered", !kept)
+	return kept
+}
+
+// targetBuckets 返回要处理的桶：没有 glob 模式时直接使用指定的桶名，否则列出所有桶并按模式匹配，最后去掉排除的桶
+// targetBuckets returns the buckets to process: bucket names are used directly when there are no glob patterns,
+// otherwise all buckets are listed and matched against the patterns, and excluded buckets are removed last
+func (c *S3Cleaner) targetBuckets(ctx context.Context) ([]string, error) {
+	var buckets []string
+	if len(c.opts.Buckets) > 0 && !slices.ContainsFunc(c.opts.Buckets, isGlob) {
+		// 只指定了桶名，不需要列出所有桶
+		// Only bucket names are given, there is no need to list all buckets
+		buckets = c.opts.Buckets
+	} else {
+		all, err := c.listBuckets(ctx)
 		if err != nil {
-			color.Red("处理桶 %s 时出错: %v\nError processing bucket %s: %v", bucket, err, bucket, err)
+			return nil, err
+		}
+		if len(c.opts.Buckets) == 0 {
+			buckets = all
+		}
+		for _, pattern := range c.opts.Buckets {
+			if !isGlob(pattern) {
+				buckets = append(buckets, pattern)
+				continue
+			}
+			matched := false
+			for _, bucket := range all {
+				if ok, _ := path.Match(pattern, bucket); ok {
+					buckets = append(buckets, bucket)
+					matched = true
+				}
+			}
+			if !matched {
+				c.log.Warn("桶模式没有匹配任何桶 | Bucket pattern matched no buckets", "pattern", pattern)
+			}
+		}
+	}
+
+	// 去掉重复和排除的桶，保持顺序
+	// Remove duplicate and excluded buckets, keeping the order
+	seen := make(map[string]bool, len(buckets))
+	result := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		if seen[bucket] || matchAnyBucket(c.opts.ExcludeBuckets, bucket) {
 			continue
 		}
-		allFiles = append(allFiles, files...)
+		seen[bucket] = true
+		result = append(result, bucket)
 	}
+	return result, nil
+}
 
-	// 根据格式输出结果
-	// Output results based on format
-	switch strings.ToLower(c.cfg.Format) {
-	case "json":
-		return c.outputJSON(allFiles)
-	case "csv":
-		return c.outputCSV(allFiles)
-	default: // table
-		return c.outputTable(allFiles)
+// isGlob 判断桶模式是否包含 glob 通配符
+// isGlob reports whether a bucket pattern contains glob wildcards
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?[")
+}
+
+// matchAnyBucket 判断桶是否匹配任一名称或 glob 模式
+// matchAnyBucket reports whether the bucket matches any of the names or glob patterns
+func matchAnyBucket(patterns []string, bucket string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, bucket)
+		return ok
+	})
+}
+
+// validateBucketPatterns 检查桶模式的语法
+// validateBucketPatterns checks the syntax of bucket patterns
+func validateBucketPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("无效的桶模式 '%s': %v\nInvalid bucket pattern '%s': %v", pattern, err, pattern, err)
+		}
 	}
+	return nil
 }
 
 // listBuckets 列出所有桶
 // listBuckets lists all buckets
-func (c *S3Cleaner) listBuckets() ([]string, error) {
-	resp, err := c.client.ListBuckets(context.TODO(), &s3.ListBucketsInput{})
+func (c *S3Cleaner) listBuckets(ctx context.Context) ([]string, error) {
+	resp, err := c.client.ListBuckets(ctx, &s3.ListBucketsInput{})
 	if err != nil {
 		return nil, fmt.Errorf("无法列出桶: %v\nFailed to list buckets: %v", err, err)
 	}
@@ -135,19 +520,21 @@ func (c *S3Cleaner) listBuckets() ([]string, error) {
 	return buckets, nil
 }
 
-// processOneBucket 处理一个桶
-// processOneBucket processes one bucket
-func (c *S3Cleaner) processOneBucket(bucket string) ([]FileInfo, error) {
-	// color.Cyan("正在处理桶: %s\nProcessing bucket: %s", bucket, bucket)
-
-	var keyMarker *string
-	var uploadIdMarker *string
+// processOneBucket 处理一个桶中的未完成分段上传，并按运行状态逐页删除
+// processOneBucket processes incomplete multipart uploads in one bucket and deletes them page by page as the run requires
+func (c *S3Cleaner) processOneBucket(ctx context.Context, r *run, bucket string) ([]FileInfo, error) {
+	// 从检查点记录的位置继续
+	// Continue from the position recorded in the checkpoint
+	markers := r.cp.markers(bucket)
+	keyMarker := optionalString(markers.KeyMarker)
+	uploadIdMarker := optionalString(markers.UploadIDMarker)
 	files := []FileInfo{}
+	page := 0
 
 	// 分页列出所有未完成的分段上传
 	// List all multipart uploads with pagination
 	for {
-		resp, err := c.client.ListMultipartUploads(context.TODO(), &s3.ListMultipartUploadsInput{
+		resp, err := c.client.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{
 			Bucket:         aws.String(bucket),
 			KeyMarker:      keyMarker,
 			UploadIdMarker: uploadIdMarker,
@@ -158,25 +545,23 @@ func (c *S3Cleaner) processOneBucket(bucket string) ([]FileInfo, error) {
 
 		// 处理当前页的未完成上传
 		// Process uploads in current page
+		page++
+		c.log.Debug("已列出一页未完成上传 | Listed a page of multipart uploads", "bucket", bucket, "page", page, "uploads", len(resp.Uploads))
+		pageStart := len(files)
 		for _, upload := range resp.Uploads {
-			// 检查是否过期
-			// Check if it's expired
-			shouldDelete := upload.Initiated.Before(c.cfg.ExpirationTime)
-
-			// 获取对象大小
-			// Get object size
-			var size int64
-			parts, err := c.client.ListParts(context.TODO(), &s3.ListPartsInput{
-				Bucket:   aws.String(bucket),
-				Key:      upload.Key,
-				UploadId: upload.UploadId,
-			})
-			if err == nil {
-				for _, part := range parts.Parts {
-					if part.Size != nil {
-						size += *part.Size
-					}
-				}
+			// 先按发起者和所有者过滤，不需要为被过滤的上传列出分段
+			// Filter by initiator and owner first, so filtered uploads need no ListParts call
+			if !c.identityAllowed(upload) {
+				c.log.Debug("按身份过滤 | Filtered by identity", "bucket", bucket, "key", aws.ToString(upload.Key),
+					"upload_id", aws.ToString(upload.UploadId), "initiator", initiatorName(upload.Initiator), "owner", ownerName(upload.Owner))
+				continue
+			}
+
+			// 获取对象大小和最新分段的时间
+			// Get object size and the time of the newest part
+			size, lastPart, partsErr := c.listParts(ctx, bucket, upload)
+			if partsErr != nil {
+				c.log.Warn("列出分段失败，大小按0计算 | ListParts failed, counting size as 0", "bucket", bucket, "key", aws.ToString(upload.Key), "upload_id", aws.ToString(upload.UploadId), "error", partsErr)
 			}
 
 			fileInfo := FileInfo{
@@ -184,19 +569,38 @@ func (c *S3Cleaner) processOneBucket(bucket string) ([]FileInfo, error) {
 				Key:          *upload.Key,
 				Size:         size,
 				ModTime:      *upload.Initiated,
-				ShouldDelete: shouldDelete,
+				Type:         FileTypeUpload,
+				StorageClass: string(upload.StorageClass),
+				Initiator:    initiatorName(upload.Initiator),
+				InitiatorID:  initiatorID(upload.Initiator),
+				Owner:        ownerName(upload.Owner),
+				UploadID:     *upload.UploadId,
+			}
+			if c.opts.AgeBasis == AgeBasisLastPart {
+				fileInfo.LastPartTime = lastPart
 			}
 
-			// 如果需要删除且do标志为true，则执行删除
-			// If should delete and do flag is true, perform deletion
-			if shouldDelete && c.cfg.DoDelete {
-				success := c.abortMultipartUpload(bucket, *upload.Key, *upload.UploadId)
-				fileInfo.DeleteSuccess = &success
+			// 检查是否过期
+			// Check if it's expired
+			fileInfo.ShouldDelete = c.stale(r, &fileInfo, fileInfo.AgeTime())
+
+			// 按最新分段判断年龄时，列出分段失败就无法确认上传是否仍在进行，保留该上传
+			// When the age is judged by the newest part and ListParts failed, the upload may still be in progress, so keep it
+			if partsErr != nil && c.opts.AgeBasis == AgeBasisLastPart && fileInfo.ShouldDelete {
+				c.log.Warn("无法确认最新分段时间，保留上传 | Newest part time unknown, keeping upload", "bucket", bucket, "key", fileInfo.Key, "upload_id", fileInfo.UploadID)
+				fileInfo.ShouldDelete = false
 			}
 
-			files = append(files, fileInfo)
+			if c.keepLogged(r, fileInfo) {
+				files = append(files, fileInfo)
+			}
 		}
 
+		// 删除当前页中需要删除的上传
+		// Delete uploads in current page that should be deleted
+		r.progress.addPage(files[pageStart:])
+		c.deleteFiles(ctx, r, files[pageStart:])
+
 		// 如果没有更多页，则退出循环
 		// If no more pages, exit loop
 		if resp.IsTruncated == nil || !*resp.IsTruncated {
@@ -204,309 +608,233 @@ func (c *S3Cleaner) processOneBucket(bucket string) ([]FileInfo, error) {
 		}
 		keyMarker = resp.NextKeyMarker
 		uploadIdMarker = resp.NextUploadIdMarker
+
+		// 当前页已处理完成，记录下一页的位置
+		// The current page is finished, record the position of the next page
+		if err := r.cp.savePage(bucket, checkpointMarkers{
+			KeyMarker:      aws.ToString(keyMarker),
+			UploadIDMarker: aws.ToString(uploadIdMarker),
+		}); err != nil {
+			return nil, err
+		}
 	}
 
 	return files, nil
 }
 
-// abortMultipartUpload 中止分段上传
-// abortMultipartUpload aborts a multipart upload
-func (c *S3Cleaner) abortMultipartUpload(bucket, key, uploadId string) bool {
-	_, err := c.client.AbortMultipartUpload(context.TODO(), &s3.AbortMultipartUploadInput{
-		Bucket:   aws.String(bucket),
-		Key:      aws.String(key),
-		UploadId: aws.String(uploadId),
-	})
-	return err == nil
-}
-
-// truncateString 截断字符串，确保中日韩文字符占两个字节
-// truncateString truncates string, ensuring CJK characters count as two bytes
-func truncateString(s string, maxBytes int) string {
-	// 计算字符串的字节宽度
-	// Calculate string byte width
-	var totalWidth int
-	for _, r := range s {
-		var runeWidth int
-		if r >= 0x4E00 && r <= 0x9FFF || // CJK统一表意文字 | CJK Unified Ideographs
-			r >= 0x3040 && r <= 0x309F || // 平假名 | Hiragana
-			r >= 0x30A0 && r <= 0x30FF || // 片假名 | Katakana
-			r >= 0x3400 && r <= 0x4DBF || // CJK统一表意文字扩展A | CJK Unified Ideographs Extension A
-			r >= 0x20000 && r <= 0x2A6DF || // CJK统一表意文字扩展B | CJK Unified Ideographs Extension B
-			r >= 0x2A700 && r <= 0x2B73F || // CJK统一表意文字扩展C | CJK Unified Ideographs Extension C
-			r >= 0x2B740 && r <= 0x2B81F || // CJK统一表意文字扩展D | CJK Unified Ideographs Extension D
-			r >= 0x2B820 && r <= 0x2CEAF || // CJK统一表意文字扩展E | CJK Unified Ideographs Extension E
-			r >= 0xAC00 && r <= 0xD7AF || // 朝鲜文音节 | Hangul Syllables
-			r >= 0xF900 && r <= 0xFAFF || // CJK兼容表意文字 | CJK Compatibility Ideographs
-			r >= 0xFF00 && r <= 0xFFEF {
-			runeWidth = 2
-		} else {
-			runeWidth = 1
+// listParts 列出分段上传的所有分段，返回总大小和最新分段的上传时间，没有分段时时间为 nil
+// listParts lists all parts of a multipart upload, returning the total size and the upload time of the newest part,
+// which is nil when there are no parts
+func (c *S3Cleaner) listParts(ctx context.Context, bucket string, upload types.MultipartUpload) (int64, *time.Time, error) {
+	var size int64
+	var lastPart *time.Time
+	var partNumberMarker *string
+	for {
+		resp, err := c.client.ListParts(ctx, &s3.ListPartsInput{
+			Bucket:           aws.String(bucket),
+			Key:              upload.Key,
+			UploadId:         upload.UploadId,
+			PartNumberMarker: partNumberMarker,
+		})
+		if err != nil {
+			return size, lastPart, err
 		}
-		totalWidth += runeWidth
-	}
-
-	// 如果总宽度不超过最大宽度，则不需要截断
-	// If total width does not exceed max width, no need to truncate
-	if totalWidth <= maxBytes {
-		return s
-	}
-
-	// 需要截断
-	// Need to truncate
-	var currentBytes int
-	var truncated []rune
-	for _, r := range s {
-		var runeWidth int
-		if r >= 0x4E00 && r <= 0x9FFF || // CJK统一表意文字 | CJK Unified Ideographs
-			r >= 0x3040 && r <= 0x309F || // 平假名 | Hiragana
-			r >= 0x30A0 && r <= 0x30FF || // 片假名 | Katakana
-			r >= 0x3400 && r <= 0x4DBF || // CJK统一表意文字扩展A | CJK Unified Ideographs Extension A
-			r >= 0x20000 && r <= 0x2A6DF || // CJK统一表意文字扩展B | CJK Unified Ideographs Extension B
-			r >= 0x2A700 && r <= 0x2B73F || // CJK统一表意文字扩展C | CJK Unified Ideographs Extension C
-			r >= 0x2B740 && r <= 0x2B81F || // CJK统一表意文字扩展D | CJK Unified Ideographs Extension D
-			r >= 0x2B820 && r <= 0x2CEAF || // CJK统一表意文字扩展E | CJK Unified Ideographs Extension E
-			r >= 0xAC00 && r <= 0xD7AF || // 朝鲜文音节 | Hangul Syllables
-			r >= 0xF900 && r <= 0xFAFF || // CJK兼容表意文字 | CJK Compatibility Ideographs
-			r >= 0xFF00 && r <= 0xFFEF {
-			runeWidth = 2
-		} else {
-			runeWidth = 1
+		for _, part := range resp.Parts {
+			size += aws.ToInt64(part.Size)
+			if part.LastModified != nil && (lastPart == nil || part.LastModified.After(*lastPart)) {
+				lastPart = part.LastModified
+			}
 		}
-
-		if currentBytes+runeWidth > maxBytes-3 { // 为省略号预留空间 | Reserve space for ellipsis
-			break
+		if resp.IsTruncated == nil || !*resp.IsTruncated || resp.NextPartNumberMarker == nil {
+			return size, lastPart, nil
 		}
+		partNumberMarker = resp.NextPartNumberMarker
+	}
+}
+
+// optionalString 将空字符串转换为 nil
+// optionalString converts an empty string to nil
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
+// initiatorName 返回上传发起者的名称，没有名称时返回其ID
+// initiatorName returns the display name of the upload initiator, or its ID if there is no name
+func initiatorName(initiator *types.Initiator) string {
+	if initiator == nil {
+		return ""
+	}
+	if initiator.DisplayName != nil && *initiator.DisplayName != "" {
+		return *initiator.DisplayName
+	}
+	return aws.ToString(initiator.ID)
+}
 
-		truncated = append(truncated, r)
-		currentBytes += runeWidth
+// initiatorID 返回上传发起者的ID
+// initiatorID returns the ID of the upload initiator
+func initiatorID(initiator *types.Initiator) string {
+	if initiator == nil {
+		return ""
 	}
+	return aws.ToString(initiator.ID)
+}
 
-	return string(truncated) + "..."
+// ownerName 返回上传所有者的名称，没有名称时返回其ID
+// ownerName returns the display name of the upload owner, or its ID if there is no name
+func ownerName(owner *types.Owner) string {
+	if owner == nil {
+		return ""
+	}
+	if owner.DisplayName != nil && *owner.DisplayName != "" {
+		return *owner.DisplayName
+	}
+	return aws.ToString(owner.ID)
 }
 
-// outputTable 以表格形式输出结果
-// outputTable outputs results in table format
-func (c *S3Cleaner) outputTable(files []FileInfo) error {
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"存储桶 | Bucket", "键 | Key", "大小 | Size", "修改时间 | Mod Time", "状态 | Status"})
-	table.SetAutoWrapText(false)
-	table.SetAutoFormatHeaders(true)
-	table.SetHeaderColor(
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-	)
+// identityAllowed 检查上传的发起者和所有者是否通过包含和排除过滤
+// identityAllowed checks whether the initiator and owner of an upload pass the include and exclude filters
+func (c *S3Cleaner) identityAllowed(upload types.MultipartUpload) bool {
+	var initiatorDisplayName, initiatorID, ownerDisplayName, ownerID string
+	if upload.Initiator != nil {
+		initiatorDisplayName, initiatorID = aws.ToString(upload.Initiator.DisplayName), aws.ToString(upload.Initiator.ID)
+	}
+	if upload.Owner != nil {
+		ownerDisplayName, ownerID = aws.ToString(upload.Owner.DisplayName), aws.ToString(upload.Owner.ID)
+	}
 
-	// 设置列宽，增加 Key 列的宽度
-	// Set column width, increase Key column width
-	table.SetColWidth(120)
+	if len(c.opts.Initiators) > 0 && !matchIdentity(c.opts.Initiators, initiatorDisplayName, initiatorID) {
+		return false
+	}
+	if matchIdentity(c.opts.ExcludeInitiators, initiatorDisplayName, initiatorID) {
+		return false
+	}
+	if len(c.opts.Owners) > 0 && !matchIdentity(c.opts.Owners, ownerDisplayName, ownerID) {
+		return false
+	}
+	return !matchIdentity(c.opts.ExcludeOwners, ownerDisplayName, ownerID)
+}
 
-	for _, file := range files {
-		// 截断过长的键
-		// Truncate long keys
-		key := file.Key
-		if utf8.RuneCountInString(key)*3 > 120 {
-			key = truncateString(key, 120)
-		}
+// matchIdentity 判断身份的名称或ID是否在列表中
+// matchIdentity reports whether the display name or ID of an identity is in the list
+func matchIdentity(identities []string, displayName, id string) bool {
+	return slices.ContainsFunc(identities, func(identity string) bool {
+		return identity != "" && (identity == displayName || identity == id)
+	})
+}
 
-		// 格式化时间
-		// Format time
-		timeStr := file.ModTime.Format("2006-01-02 15:04:05")
-
-		// 格式化大小
-		// Format size
-		sizeStr := formatSize(file.Size)
-
-		// 格式化状态，使用表情符号和文字
-		// Format status with emoji and text
-		var statusStr string
-		var statusColor tablewriter.Colors
-
-		if file.DeleteSuccess == nil {
-			// 未执行删除操作时，显示是否会被命中删除
-			// When deletion is not executed, show if it would be targeted for deletion
-			if file.ShouldDelete {
-				statusStr = "🎯 Will delete" // 会被命中删除 | Would be targeted for deletion
-				statusColor = tablewriter.Colors{tablewriter.FgHiRedColor}
-			} else {
-				statusStr = "🔍 Won't delete" // 不会被命中删除 | Would not be targeted for deletion
-				statusColor = tablewriter.Colors{tablewriter.FgYellowColor}
-			}
-		} else if *file.DeleteSuccess {
-			statusStr = "✅ Deleted" // 删除成功 | Deletion Success
-			statusColor = tablewriter.Colors{tablewriter.FgGreenColor}
-		} else {
-			statusStr = "❌ Delete failed" // 删除失败 | Deletion Failed
-			statusColor = tablewriter.Colors{tablewriter.FgRedColor}
-		}
+// deleteFiles 如果本次运行需要删除，在删除预算内删除需要删除的文件：分段上传逐个中止，对象按桶批量删除
+// deleteFiles deletes files that should be deleted within the delete budget if the run deletes:
+// multipart uploads are aborted one by one, objects are deleted in batches per bucket
+func (c *S3Cleaner) deleteFiles(ctx context.Context, r *run, files []FileInfo) {
+	if !r.doDelete {
+		return
+	}
 
-		// 根据是否应删除设置时间列的颜色
-		// Set time column color based on should delete
-		var timeColor tablewriter.Colors
-		if file.ShouldDelete {
-			timeColor = tablewriter.Colors{tablewriter.FgHiRedColor}
-		} else {
-			timeColor = tablewriter.Colors{tablewriter.FgYellowColor}
+	var buckets []string
+	var attempted []*FileInfo
+	objectsByBucket := map[string][]*FileInfo{}
+	for i := range files {
+		file := &files[i]
+		if !file.ShouldDelete || file.DeleteSuccess != nil {
+			continue
 		}
 
-		row := []string{file.Bucket, key, sizeStr, timeStr, statusStr}
-		colors := []tablewriter.Colors{
-			tablewriter.Colors{tablewriter.FgHiBlueColor},
-			tablewriter.Colors{tablewriter.FgWhiteColor},
-			tablewriter.Colors{tablewriter.FgHiCyanColor},
-			timeColor,
-			statusColor,
+		if !r.budget.allow(file.Size) {
+			c.log.Debug("超出删除预算，跳过 | Over the delete budget, skipping", "bucket", file.Bucket, "key", file.Key, "size", file.Size)
+			file.Skipped = true
+			r.cp.hold(file.Bucket)
+			continue
 		}
+		r.budget.consume(file.Size)
+		attempted = append(attempted, file)
 
-		table.Rich(row, colors)
-	}
-
-	if len(files) == 0 {
-		color.Yellow("未找到临时文件\nNo temporary files found")
-	} else {
-		table.Render()
-
-		// 计算总容量和应删除的容量
-		// Calculate total size and size to delete
-		var totalSize, sizeToDelete, sizeDeleted int64
-		var countToDelete, countDeleted int
-		for _, file := range files {
-			totalSize += file.Size
-			if file.ShouldDelete {
-				sizeToDelete += file.Size
-				countToDelete++
-			}
-			// 计算已删除的文件数量和大小
-			// Calculate deleted files count and size
-			if file.DeleteSuccess != nil && *file.DeleteSuccess {
-				sizeDeleted += file.Size
-				countDeleted++
-			}
+		if file.Type == FileTypeUpload {
+			success := c.abortMultipartUpload(ctx, file.Bucket, file.Key, file.UploadID)
+			file.DeleteSuccess = &success
+			continue
 		}
 
-		// 输出统计信息
-		// Output statistics
-		fmt.Println()
-
-		// 创建统计信息表格
-		// Create statistics table
-		statTable := tablewriter.NewWriter(os.Stdout)
-		statTable.SetHeader([]string{"统计信息 | Statistics", "值 | Value"})
-		statTable.SetAutoWrapText(false)
-		statTable.SetAutoFormatHeaders(true)
-		statTable.SetHeaderColor(
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
-		)
-		statTable.SetColumnColor(
-			tablewriter.Colors{tablewriter.FgHiWhiteColor},
-			tablewriter.Colors{tablewriter.FgHiWhiteColor},
-		)
-
-		// 添加统计数据行
-		// Add statistics data rows
-		statTable.Append([]string{"总文件数 | Total files", fmt.Sprintf("%d", len(files))})
-		statTable.Append([]string{"总容量 | Total size", formatSize(totalSize)})
-		statTable.Append([]string{"应删除文件数 | Files to delete", fmt.Sprintf("%d", countToDelete)})
-		statTable.Append([]string{"应删除容量 | Size to delete", formatSize(sizeToDelete)})
-		statTable.Append([]string{"已删除文件数 | Files deleted", fmt.Sprintf("%d", countDeleted)})
-		statTable.Append([]string{"已删除容量 | Size deleted", formatSize(sizeDeleted)})
-
-		// 渲染统计表格
-		// Render statistics table
-		statTable.Render()
+		if _, ok := objectsByBucket[file.Bucket]; !ok {
+			buckets = append(buckets, file.Bucket)
+		}
+		objectsByBucket[file.Bucket] = append(objectsByBucket[file.Bucket], file)
 	}
-	return nil
-}
 
-// formatSize 格式化文件大小
-// formatSize formats file size
-func formatSize(size int64) string {
-	const (
-		B  = 1
-		KB = 1024 * B
-		MB = 1024 * KB
-		GB = 1024 * MB
-		TB = 1024 * GB
-	)
-
-	switch {
-	case size >= TB:
-		return fmt.Sprintf("%.2f TB", float64(size)/float64(TB))
-	case size >= GB:
-		return fmt.Sprintf("%.2f GB", float64(size)/float64(GB))
-	case size >= MB:
-		return fmt.Sprintf("%.2f MB", float64(size)/float64(MB))
-	case size >= KB:
-		return fmt.Sprintf("%.2f KB", float64(size)/float64(KB))
-	default:
-		return fmt.Sprintf("%d B", size)
+	for _, bucket := range buckets {
+		c.deleteObjects(ctx, bucket, objectsByBucket[bucket])
 	}
-}
 
-// outputJSON 以JSON格式输出结果
-// outputJSON outputs results in JSON format
-func (c *S3Cleaner) outputJSON(files []FileInfo) error {
-	result := struct {
-		Files []FileInfo `json:"files"`
-		Total int        `json:"total"`
-	}{
-		Files: files,
-		Total: len(files),
+	r.progress.addDeleted(attempted)
+	if c.opts.OnDelete != nil {
+		for _, file := range attempted {
+			c.opts.OnDelete(*file)
+		}
 	}
+}
 
-	jsonData, err := json.MarshalIndent(result, "", "  ")
+// abortMultipartUpload 中止分段上传
+// abortMultipartUpload aborts a multipart upload
+func (c *S3Cleaner) abortMultipartUpload(ctx context.Context, bucket, key, uploadId string) bool {
+	_, err := c.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadId),
+	})
 	if err != nil {
-		return fmt.Errorf("无法序列化为JSON: %v\nFailed to serialize to JSON: %v", err, err)
+		c.log.Warn("中止分段上传失败 | AbortMultipartUpload failed", "bucket", bucket, "key", key, "upload_id", uploadId, "error", err)
+		return false
 	}
-
-	fmt.Println(string(jsonData))
-	return nil
+	return true
 }
 
-// outputCSV 以CSV格式输出结果
-// outputCSV outputs results in CSV format
-func (c *S3Cleaner) outputCSV(files []FileInfo) error {
-	writer := csv.NewWriter(os.Stdout)
-	defer writer.Flush()
-
-	// 写入表头
-	// Write header
-	if err := writer.Write([]string{"Bucket", "Key", "Size", "ModTime", "ShouldDelete", "DeleteSuccess"}); err != nil {
-		return fmt.Errorf("无法写入CSV表头: %v\nFailed to write CSV header: %v", err, err)
-	}
+// Statistics 统计信息
+// Statistics contains aggregated counters of the scanned files
+type Statistics struct {
+	TotalFiles    int   `json:"total_files"`
+	TotalSize     int64 `json:"total_size"`
+	FilesToDelete int   `json:"files_to_delete"`
+	SizeToDelete  int64 `json:"size_to_delete"`
+	FilesDeleted  int   `json:"files_deleted"`
+	SizeDeleted   int64 `json:"size_deleted"`
+	FilesFailed   int   `json:"files_failed"`
+	SizeFailed    int64 `json:"size_failed"`
+}
 
-	// 写入数据
-	// Write data
+// ComputeStatistics 计算文件列表的统计信息
+// ComputeStatistics calculates statistics of the file list
+func ComputeStatistics(files []FileInfo) Statistics {
+	var stats Statistics
 	for _, file := range files {
-		shouldDelete := "false"
+		stats.TotalFiles++
+		stats.TotalSize += file.Size
 		if file.ShouldDelete {
-			shouldDelete = "true"
+			stats.SizeToDelete += file.Size
+			stats.FilesToDelete++
 		}
-
-		deleteSuccess := "not_executed"
+		// 计算已删除和删除失败的文件数量和大小
+		// Calculate deleted and failed files count and size
 		if file.DeleteSuccess != nil {
 			if *file.DeleteSuccess {
-				deleteSuccess = "true"
+				stats.SizeDeleted += file.Size
+				stats.FilesDeleted++
 			} else {
-				deleteSuccess = "false"
+				stats.SizeFailed += file.Size
+				stats.FilesFailed++
 			}
 		}
-
-		if err := writer.Write([]string{
-			file.Bucket,
-			file.Key,
-			fmt.Sprintf("%d", file.Size),
-			file.ModTime.Format(time.RFC3339),
-			shouldDelete,
-			deleteSuccess,
-		}); err != nil {
-			return fmt.Errorf("无法写入CSV数据: %v\nFailed to write CSV data: %v", err, err)
-		}
 	}
+	return stats
+}
 
-	return nil
+// ruleNames 返回策略中所有规则的名称，没有规则时返回 nil
+// ruleNames returns the names of all policy rules, or nil when there are none
+func (c *S3Cleaner) ruleNames() []string {
+	var names []string
+	for _, rule := range c.rules {
+		names = append(names, rule.Name)
+	}
+	return names
 }
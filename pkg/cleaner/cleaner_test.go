@@ -0,0 +1,320 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// fakeS3 只实现分段上传相关方法的假S3客户端，调用其他方法会 panic
+// fakeS3 is a fake S3 client implementing only the multipart upload methods, other methods panic
+type fakeS3 struct {
+	S3API
+
+	mu       sync.Mutex
+	buckets  []string
+	uploads  map[string][]types.MultipartUpload
+	parts    map[string][]types.Part
+	partsErr error
+	aborted  []string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{uploads: map[string][]types.MultipartUpload{}, parts: map[string][]types.Part{}}
+}
+
+// addUpload 添加一个分段上传，parts 为各分段的上传时间，每个分段 1KiB
+// addUpload adds a multipart upload, parts are the upload times of its parts, each part being 1KiB
+func (f *fakeS3) addUpload(bucket, key, uploadID string, initiated time.Time, parts ...time.Time) {
+	if !slices.Contains(f.buckets, bucket) {
+		f.buckets = append(f.buckets, bucket)
+	}
+	f.uploads[bucket] = append(f.uploads[bucket], types.MultipartUpload{
+		Key:       aws.String(key),
+		UploadId:  aws.String(uploadID),
+		Initiated: aws.Time(initiated),
+	})
+	for i, part := range parts {
+		f.parts[uploadID] = append(f.parts[uploadID], types.Part{
+			PartNumber:   aws.Int32(int32(i + 1)),
+			Size:         aws.Int64(1024),
+			LastModified: aws.Time(part),
+		})
+	}
+}
+
+func (f *fakeS3) ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error) {
+	out := &s3.ListBucketsOutput{}
+	for _, bucket := range f.buckets {
+		out.Buckets = append(out.Buckets, types.Bucket{Name: aws.String(bucket)})
+	}
+	return out, nil
+}
+
+func (f *fakeS3) ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error) {
+	return &s3.ListMultipartUploadsOutput{Uploads: f.uploads[aws.ToString(params.Bucket)]}, nil
+}
+
+func (f *fakeS3) ListParts(ctx context.Context, params *s3.ListPartsInput, optFns ...func(*s3.Options)) (*s3.ListPartsOutput, error) {
+	if f.partsErr != nil {
+		return nil, f.partsErr
+	}
+	return &s3.ListPartsOutput{Parts: f.parts[aws.ToString(params.UploadId)]}, nil
+}
+
+func (f *fakeS3) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.aborted = append(f.aborted, aws.ToString(params.UploadId))
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// shouldDelete 返回结果中每个键是否需要删除
+// shouldDelete returns whether each key in the result should be deleted
+func shouldDelete(result *Result) map[string]bool {
+	decisions := map[string]bool{}
+	for _, file := range result.Files {
+		decisions[file.Key] = file.ShouldDelete
+	}
+	return decisions
+}
+
+func TestCleanRulesWithSortBy(t *testing.T) {
+	now := time.Now()
+	for _, sortBy := range []string{"", "size", "age", "key"} {
+		t.Run("sortBy="+sortBy, func(t *testing.T) {
+			client := newFakeS3()
+			client.addUpload("bkt", "tmp/old.bin", "u1", now.Add(-2*time.Hour))
+			client.addUpload("bkt", "tmp/new.bin", "u2", now.Add(-10*time.Minute))
+			client.addUpload("bkt", "data/old.bin", "u3", now.Add(-2*time.Hour))
+			client.addUpload("bkt", "keep/old.bin", "u4", now.Add(-30*24*time.Hour))
+
+			opts := DefaultOptions()
+			opts.Client = client
+			opts.SortBy = sortBy
+			opts.Rules = []Rule{
+				{Name: "tmp", Prefix: "tmp/", Action: RuleActionAbort, OlderThan: time.Hour},
+				{Name: "keep", Prefix: "keep/", Action: RuleActionKeep},
+			}
+			c, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := c.Clean(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]bool{"tmp/old.bin": true, "tmp/new.bin": false, "data/old.bin": false, "keep/old.bin": false}
+			if got := shouldDelete(result); !maps.Equal(got, want) {
+				t.Errorf("ShouldDelete = %v, want %v", got, want)
+			}
+			if !slices.Equal(client.aborted, []string{"u1"}) {
+				t.Errorf("aborted = %v, want [u1]", client.aborted)
+			}
+		})
+	}
+}
+
+func TestAgeBasisLastPart(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		ageBasis string
+		partsErr error
+		want     map[string]bool
+	}{
+		{"initiated", AgeBasisInitiated, nil, map[string]bool{"idle.bin": true, "active.bin": true}},
+		{"lastPart", AgeBasisLastPart, nil, map[string]bool{"idle.bin": true, "active.bin": false}},
+		{"lastPart with ListParts error", AgeBasisLastPart, errors.New("throttled"), map[string]bool{"idle.bin": false, "active.bin": false}},
+		{"initiated with ListParts error", AgeBasisInitiated, errors.New("throttled"), map[string]bool{"idle.bin": true, "active.bin": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeS3()
+			client.addUpload("bkt", "idle.bin", "u1", now.Add(-10*24*time.Hour), now.Add(-9*24*time.Hour))
+			client.addUpload("bkt", "active.bin", "u2", now.Add(-10*24*time.Hour), now.Add(-9*24*time.Hour), now.Add(-time.Hour))
+			client.partsErr = tt.partsErr
+
+			opts := DefaultOptions()
+			opts.Client = client
+			opts.AgeBasis = tt.ageBasis
+			c, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := c.Scan(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := shouldDelete(result); !maps.Equal(got, tt.want) {
+				t.Errorf("ShouldDelete = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdentityAllowed(t *testing.T) {
+	upload := types.MultipartUpload{
+		Initiator: &types.Initiator{DisplayName: aws.String("alice"), ID: aws.String("arn:aws:iam::123456789012:user/alice")},
+		Owner:     &types.Owner{DisplayName: aws.String("team"), ID: aws.String("owner-id")},
+	}
+	noNames := types.MultipartUpload{
+		Initiator: &types.Initiator{ID: aws.String("arn:aws:iam::123456789012:user/alice")},
+		Owner:     &types.Owner{ID: aws.String("owner-id")},
+	}
+
+	tests := []struct {
+		name   string
+		opts   Options
+		upload types.MultipartUpload
+		want   bool
+	}{
+		{"no filters", Options{}, upload, true},
+		{"initiator by display name", Options{Initiators: []string{"bob", "alice"}}, upload, true},
+		{"initiator by ID", Options{Initiators: []string{"arn:aws:iam::123456789012:user/alice"}}, upload, true},
+		{"initiator by ID without display name", Options{Initiators: []string{"arn:aws:iam::123456789012:user/alice"}}, noNames, true},
+		{"other initiator", Options{Initiators: []string{"bob"}}, upload, false},
+		{"excluded initiator by display name", Options{ExcludeInitiators: []string{"alice"}}, upload, false},
+		{"excluded initiator by ID", Options{ExcludeInitiators: []string{"arn:aws:iam::123456789012:user/alice"}}, upload, false},
+		{"other excluded initiator", Options{ExcludeInitiators: []string{"bob"}}, upload, true},
+		{"owner by display name", Options{Owners: []string{"team"}}, upload, true},
+		{"owner by ID", Options{Owners: []string{"owner-id"}}, noNames, true},
+		{"other owner", Options{Owners: []string{"other"}}, upload, false},
+		{"excluded owner by display name", Options{ExcludeOwners: []string{"team"}}, upload, false},
+		{"excluded owner by ID", Options{ExcludeOwners: []string{"owner-id"}}, upload, false},
+		{"included initiator with excluded owner", Options{Initiators: []string{"alice"}, ExcludeOwners: []string{"team"}}, upload, false},
+		{"empty filter value never matches", Options{Initiators: []string{""}}, types.MultipartUpload{}, false},
+		{"missing initiator is not excluded", Options{ExcludeInitiators: []string{"alice"}}, types.MultipartUpload{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &S3Cleaner{opts: tt.opts}
+			if got := c.identityAllowed(tt.upload); got != tt.want {
+				t.Errorf("identityAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetBuckets(t *testing.T) {
+	client := newFakeS3()
+	client.buckets = []string{"logs-1", "logs-2", "logs-keep", "data", "backup-1"}
+
+	tests := []struct {
+		name    string
+		buckets []string
+		exclude []string
+		want    []string
+	}{
+		{"all buckets", nil, nil, []string{"logs-1", "logs-2", "logs-keep", "data", "backup-1"}},
+		{"names are used as given", []string{"data", "missing"}, nil, []string{"data", "missing"}},
+		{"glob", []string{"logs-*"}, nil, []string{"logs-1", "logs-2", "logs-keep"}},
+		{"glob with exclusion", []string{"logs-*"}, []string{"logs-keep"}, []string{"logs-1", "logs-2"}},
+		{"exclusion glob", nil, []string{"logs-?", "backup-*"}, []string{"logs-keep", "data"}},
+		{"names and globs without duplicates", []string{"data", "logs-[12]", "logs-1", "*a"}, nil, []string{"data", "logs-1", "logs-2"}},
+		{"glob matching nothing", []string{"nothing-*"}, nil, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Client = client
+			opts.Buckets = tt.buckets
+			opts.ExcludeBuckets = tt.exclude
+			c, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.targetBuckets(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("targetBuckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidBucketPattern(t *testing.T) {
+	for _, opts := range []Options{{Buckets: []string{"logs-["}}, {ExcludeBuckets: []string{"logs-["}}} {
+		opts.Client = newFakeS3()
+		if _, err := New(opts); err == nil || !strings.Contains(err.Error(), "Invalid bucket pattern 'logs-['") {
+			t.Errorf("New(%v, %v) error = %v, want an invalid pattern error", opts.Buckets, opts.ExcludeBuckets, err)
+		}
+	}
+}
+
+func TestCleanResumesDeleteBudget(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	client := newFakeS3()
+	client.addUpload("a", "x.bin", "u1", old, old)
+	client.addUpload("b", "y.bin", "u2", old, old)
+
+	opts := DefaultOptions()
+	opts.Client = client
+	opts.MaxDeleteCount = 2
+	opts.Checkpoint = filepath.Join(t.TempDir(), "checkpoint.json")
+
+	// 中断的运行已经删除了一个文件并完成了桶 a
+	// The interrupted run already deleted one file and finished bucket a
+	cp, err := loadCheckpoint(opts.Checkpoint, newCheckpointSettings(opts, true), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.DeletedCount, cp.DeletedBytes = 1, 1024
+	if err := cp.finishBucket("a"); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Clean(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(client.aborted, []string{"u2"}) {
+		t.Errorf("aborted = %v, want [u2]", client.aborted)
+	}
+
+	// 预算已用完时继续的运行不再删除 | A resumed run with the budget used up deletes nothing more
+	client.aborted = nil
+	opts.MaxDeleteCount = 1
+	cp, err = loadCheckpoint(opts.Checkpoint, newCheckpointSettings(opts, true), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.DeletedCount, cp.DeletedBytes = 1, 1024
+	if err := cp.finishBucket("a"); err != nil {
+		t.Fatal(err)
+	}
+	c, err = New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := c.Clean(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(client.aborted) != 0 || len(result.Files) != 1 || !result.Files[0].Skipped {
+		t.Errorf("aborted = %v, files = %+v, want y.bin skipped over the budget", client.aborted, result.Files)
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// 重试模式
+// Retry modes
+const (
+	RetryModeStandard = "standard"
+	RetryModeAdaptive = "adaptive"
+)
+
+// S3API 清理器使用的S3接口，*s3.Client 实现了该接口，也可以替换为其他实现（如测试用的假客户端）
+// S3API is the S3 interface used by the cleaner, implemented by *s3.Client and replaceable by other
+// implementations such as a fake client in tests
+type S3API interface {
+	ListBuckets(ctx context.Context, params *s3.ListBucketsInput, optFns ...func(*s3.Options)) (*s3.ListBucketsOutput, error)
+	ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error)
+	ListParts(ctx context.Context, params *s3.ListPartsInput, optFns ...func(*s3.Options)) (*s3.ListPartsOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	ListObjectVersions(ctx context.Context, params *s3.ListObjectVersionsInput, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
+	GetBucketLifecycleConfiguration(ctx context.Context, params *s3.GetBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.GetBucketLifecycleConfigurationOutput, error)
+	PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+	DeleteBucketLifecycle(ctx context.Context, params *s3.DeleteBucketLifecycleInput, optFns ...func(*s3.Options)) (*s3.DeleteBucketLifecycleOutput, error)
+}
+
+var _ S3API = (*s3.Client)(nil)
+
+// countingRetryer 统计重试次数的重试器
+// countingRetryer is a retryer that counts retries
+type countingRetryer struct {
+	aws.RetryerV2
+	retries *atomic.Int64
+	log     *slog.Logger
+}
+
+// RetryDelay 只在确定要重试时调用，因此在这里计数并记录日志
+// RetryDelay is only called when a retry will be made, so retries are counted and logged here
+func (r countingRetryer) RetryDelay(attempt int, err error) (time.Duration, error) {
+	r.retries.Add(1)
+	r.log.Debug("重试请求 | Retrying request", "attempt", attempt, "error", err)
+	return r.RetryerV2.RetryDelay(attempt, err)
+}
+
+// newRetryer 根据选项创建重试器，maxRetries 为首次请求之外的重试次数
+// newRetryer creates a retryer from options, maxRetries is the number of retries after the first attempt
+func newRetryer(opts Options, retries *atomic.Int64, logger *slog.Logger) (func() aws.Retryer, error) {
+	if opts.MaxRetries < 0 {
+		return nil, fmt.Errorf("无效的最大重试次数 %d，不能为负数\nInvalid maximum retries %d, must not be negative", opts.MaxRetries, opts.MaxRetries)
+	}
+
+	standardOptions := func(o *retry.StandardOptions) {
+		o.MaxAttempts = opts.MaxRetries + 1
+	}
+
+	var newRetryer func() aws.RetryerV2
+	switch opts.RetryMode {
+	case "", RetryModeStandard:
+		newRetryer = func() aws.RetryerV2 {
+			return retry.NewStandard(standardOptions)
+		}
+	case RetryModeAdaptive:
+		newRetryer = func() aws.RetryerV2 {
+			return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+				o.StandardOptions = append(o.StandardOptions, standardOptions)
+			})
+		}
+	default:
+		return nil, fmt.Errorf("无效的重试模式 '%s'，有效选项为: standard, adaptive\nInvalid retry mode '%s', valid options are: standard, adaptive", opts.RetryMode, opts.RetryMode)
+	}
+
+	return func() aws.Retryer {
+		return countingRetryer{RetryerV2: newRetryer(), retries: retries, log: logger}
+	}, nil
+}
+
+// newS3Client 根据选项创建S3客户端，应用重试策略和超时设置
+// newS3Client creates an S3 client from options, applying the retry policy and timeouts
+func newS3Client(opts Options, retries *atomic.Int64, logger *slog.Logger) (S3API, error) {
+	retryer, err := newRetryer(opts, retries, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// 连接超时限制建立连接的时间，请求超时限制每次尝试的总时间，超时的尝试会被重试
+	// The connect timeout limits establishing a connection, the request timeout limits each attempt,
+	// and attempts that time out are retried
+	httpClient := awshttp.NewBuildableClient().
+		WithDialerOptions(func(d *net.Dialer) {
+			d.Timeout = opts.ConnectTimeout
+		}).
+		WithTimeout(opts.RequestTimeout)
+
+	// 创建AWS配置
+	// Create AWS configuration
+	loadOpts := []func(*awsconfig.LoadOptions) error{
+		awsconfig.WithRegion("us-east-1"), // 默认区域，会根据桶自动调整 | Default region, will be adjusted automatically based on bucket
+		awsconfig.WithRetryer(retryer),
+		awsconfig.WithHTTPClient(httpClient),
+	}
+	// 没有指定密钥时使用默认凭证链（环境变量、共享配置、IRSA、实例角色等）
+	// Without keys the default credential chain is used (environment, shared config, IRSA, instance roles and so on)
+	if opts.AccessKey != "" || opts.SecretKey != "" {
+		loadOpts = append(loadOpts, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(opts.AccessKey, opts.SecretKey, "")))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.TODO(), loadOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("无法加载AWS配置: %v\nFailed to load AWS configuration: %v", err, err)
+	}
+
+	// 创建S3客户端
+	// Create S3 client
+	return s3.NewFromConfig(awsCfg), nil
+}
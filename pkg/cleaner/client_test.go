@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestNewS3ClientCredentials(t *testing.T) {
+	// 隔离共享配置和实例元数据，只保留环境变量中的凭证
+	// Isolate the shared config and instance metadata, leaving only the credentials in the environment
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("AWS_CONFIG_FILE", missing)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", missing)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_ACCESS_KEY_ID", "env-ak")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-sk")
+
+	tests := []struct {
+		name           string
+		accessKey      string
+		secretKey      string
+		wantAK, wantSK string
+	}{
+		{"static keys", "static-ak", "static-sk", "static-ak", "static-sk"},
+		{"default credential chain without keys", "", "", "env-ak", "env-sk"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newS3Client(Options{AccessKey: tt.accessKey, SecretKey: tt.secretKey}, &atomic.Int64{}, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatal(err)
+			}
+			creds, err := client.(*s3.Client).Options().Credentials.Retrieve(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds.AccessKeyID != tt.wantAK || creds.SecretAccessKey != tt.wantSK {
+				t.Errorf("credentials = %s/%s, want %s/%s", creds.AccessKeyID, creds.SecretAccessKey, tt.wantAK, tt.wantSK)
+			}
+		})
+	}
+}
+
+func TestCountingRetryerCountsOnlyRetries(t *testing.T) {
+	retries := &atomic.Int64{}
+	newRetryer, err := newRetryer(Options{MaxRetries: 3}, retries, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatal(err)
+	}
+	retryer := newRetryer().(aws.RetryerV2)
+
+	// 判断错误是否可重试不算重试 | Asking whether an error is retryable is not a retry
+	for range 5 {
+		retryer.IsErrorRetryable(errors.New("connection reset"))
+	}
+	if got := retries.Load(); got != 0 {
+		t.Fatalf("retries after IsErrorRetryable = %d, want 0", got)
+	}
+	if _, err := retryer.RetryDelay(1, errors.New("connection reset")); err != nil {
+		t.Fatal(err)
+	}
+	if got := retries.Load(); got != 1 {
+		t.Errorf("retries after RetryDelay = %d, want 1", got)
+	}
+}
+
+func TestCountingRetryerCountsRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		status      int
+		wantRetries int64
+		wantErr     bool
+	}{
+		{name: "success", wantRetries: 0},
+		{name: "retried server error", failures: 1, status: http.StatusServiceUnavailable, wantRetries: 1},
+		{name: "access denied is not retried", failures: 1, status: http.StatusForbidden, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/xml")
+				if requests <= tt.failures {
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`<Error><Code>Failure</Code><Message>failure</Message></Error>`))
+					return
+				}
+				w.Write([]byte(`<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>`))
+			}))
+			defer server.Close()
+			t.Setenv("AWS_ENDPOINT_URL", server.URL)
+
+			retries := &atomic.Int64{}
+			client, err := newS3Client(Options{AccessKey: "ak", SecretKey: "sk", MaxRetries: 3}, retries, slog.New(slog.DiscardHandler))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = client.ListBuckets(context.Background(), &s3.ListBucketsInput{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListBuckets() error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := retries.Load(); got != tt.wantRetries {
+				t.Errorf("retries = %d, want %d after %d requests", got, tt.wantRetries, requests)
+			}
+		})
+	}
+}
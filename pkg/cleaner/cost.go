@@ -0,0 +1,128 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"maps"
+	"time"
+)
+
+// bytesPerGB 计费使用的 GB 大小
+// bytesPerGB is the size of a GB used for billing
+const bytesPerGB = 1 << 30
+
+// billingMonth 按月计费时一个月的时长
+// billingMonth is the length of a month for monthly billing
+const billingMonth = 30 * 24 * time.Hour
+
+// Pricing 每种存储类型每 GB 每月的存储价格
+// Pricing holds the storage price per GB-month of each storage class
+type Pricing struct {
+	// Currency 价格的货币单位
+	// Currency of the prices
+	Currency string
+
+	// Default 未在 Classes 中列出的存储类型的价格，对象没有存储类型时按 STANDARD 计算
+	// Price of storage classes not listed in Classes, objects without a storage class are billed as STANDARD
+	Default float64
+
+	// Classes 各存储类型的价格，键为 S3 存储类型名称，如 STANDARD_IA
+	// Prices of each storage class, keyed by the S3 storage class name, e.g. STANDARD_IA
+	Classes map[string]float64
+}
+
+// DefaultPricing 返回缤纷云S4的默认存储价格
+// DefaultPricing returns the default storage prices of Bitiful S4
+func DefaultPricing() Pricing {
+	return Pricing{
+		Currency: "CNY",
+		Default:  0.12,
+		Classes: map[string]float64{
+			"STANDARD":     0.12,
+			"STANDARD_IA":  0.08,
+			"GLACIER":      0.033,
+			"DEEP_ARCHIVE": 0.015,
+		},
+	}
+}
+
+// WithOverrides 返回用 classes 覆盖后的价格，不修改 p
+// WithOverrides returns the pricing with classes overridden, leaving p unchanged
+func (p Pricing) WithOverrides(classes map[string]float64) Pricing {
+	p.Classes = maps.Clone(p.Classes)
+	if p.Classes == nil {
+		p.Classes = map[string]float64{}
+	}
+	maps.Copy(p.Classes, classes)
+	return p
+}
+
+// Price 返回存储类型每 GB 每月的价格
+// Price returns the price per GB-month of a storage class
+func (p Pricing) Price(storageClass string) float64 {
+	if storageClass == "" {
+		storageClass = "STANDARD"
+	}
+	if price, ok := p.Classes[storageClass]; ok {
+		return price
+	}
+	return p.Default
+}
+
+// MonthlyCost 返回文件每月的存储成本
+// MonthlyCost returns the monthly storage cost of a file
+func (p Pricing) MonthlyCost(file FileInfo) float64 {
+	return float64(file.Size) / bytesPerGB * p.Price(file.StorageClass)
+}
+
+// AccumulatedCost 返回文件从修改时间（分段上传为发起时间）到 now 累计的存储成本
+// AccumulatedCost returns the storage cost a file has accumulated from its mod time
+// (the initiation time for multipart uploads) until now
+func (p Pricing) AccumulatedCost(file FileInfo, now time.Time) float64 {
+	age := now.Sub(file.ModTime)
+	if age < 0 {
+		return 0
+	}
+	return p.MonthlyCost(file) * float64(age) / float64(billingMonth)
+}
+
+// Cost 过期文件的存储成本估算
+// Cost is the estimated storage cost of stale files
+type Cost struct {
+	// Currency 成本的货币单位
+	// Currency of the costs
+	Currency string `json:"currency"`
+
+	// StaleMonthly 过期文件当前每月的存储成本
+	// Current monthly storage cost of stale files
+	StaleMonthly float64 `json:"stale_monthly"`
+
+	// RemovedMonthly 本次运行删除的文件每月的存储成本
+	// Monthly storage cost of the files deleted by this run
+	RemovedMonthly float64 `json:"removed_monthly"`
+
+	// StaleAccumulated 过期文件到目前为止累计的存储成本
+	// Storage cost accumulated so far by stale files
+	StaleAccumulated float64 `json:"stale_accumulated"`
+}
+
+// ComputeCost 按价格估算文件列表的存储成本，累计成本计算到 now
+// ComputeCost estimates the storage cost of the file list with the pricing, accumulating costs until now
+func ComputeCost(files []FileInfo, pricing Pricing, now time.Time) Cost {
+	cost := Cost{Currency: pricing.Currency}
+	for _, file := range files {
+		if file.ShouldDelete {
+			cost.StaleMonthly += pricing.MonthlyCost(file)
+			cost.StaleAccumulated += pricing.AccumulatedCost(file, now)
+		}
+		if file.DeleteSuccess != nil && *file.DeleteSuccess {
+			cost.RemovedMonthly += pricing.MonthlyCost(file)
+		}
+	}
+	return cost
+}
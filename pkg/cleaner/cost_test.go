@@ -0,0 +1,75 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestPricingMonthlyCost(t *testing.T) {
+	pricing := DefaultPricing().WithOverrides(map[string]float64{"GLACIER": 0.02, "INTELLIGENT_TIERING": 0.1})
+
+	tests := []struct {
+		storageClass string
+		want         float64
+	}{
+		{"", 0.12},
+		{"STANDARD", 0.12},
+		{"STANDARD_IA", 0.08},
+		{"GLACIER", 0.02},
+		{"DEEP_ARCHIVE", 0.015},
+		{"INTELLIGENT_TIERING", 0.1},
+		{"UNKNOWN_CLASS", 0.12},
+	}
+	for _, tt := range tests {
+		t.Run(tt.storageClass, func(t *testing.T) {
+			file := FileInfo{Size: 2 * bytesPerGB, StorageClass: tt.storageClass}
+			if got := pricing.MonthlyCost(file); math.Abs(got-2*tt.want) > 1e-9 {
+				t.Errorf("MonthlyCost() = %v, want %v", got, 2*tt.want)
+			}
+		})
+	}
+
+	// 覆盖价格不修改原价格 | Overrides leave the original pricing unchanged
+	if got := DefaultPricing().Price("GLACIER"); got != 0.033 {
+		t.Errorf("default GLACIER price = %v, want 0.033", got)
+	}
+	// 未知存储类型使用 Default | Unknown storage classes use Default
+	custom := Pricing{Default: 0.5}
+	if got := custom.Price("UNKNOWN_CLASS"); got != 0.5 {
+		t.Errorf("Price(unknown) = %v, want the default 0.5", got)
+	}
+}
+
+func TestComputeCost(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	pricing := Pricing{Currency: "USD", Default: 1, Classes: map[string]float64{"STANDARD_IA": 0.5}}
+	files := []FileInfo{
+		// 过期且已删除，存在半个计费月 | Stale and deleted, stored for half a billing month
+		{Size: bytesPerGB, ModTime: now.Add(-billingMonth / 2), ShouldDelete: true, DeleteSuccess: aws.Bool(true)},
+		// 过期但删除失败 | Stale but failed to delete
+		{Size: 2 * bytesPerGB, StorageClass: "STANDARD_IA", ModTime: now.Add(-billingMonth), ShouldDelete: true, DeleteSuccess: aws.Bool(false)},
+		// 未过期，不计入 | Not stale, not counted
+		{Size: 4 * bytesPerGB, ModTime: now.Add(-time.Hour)},
+		// 修改时间在 now 之后，累计成本为 0 | Mod time after now, no accumulated cost
+		{Size: bytesPerGB, StorageClass: "UNKNOWN_CLASS", ModTime: now.Add(time.Hour), ShouldDelete: true},
+	}
+
+	cost := ComputeCost(files, pricing, now)
+	want := Cost{Currency: "USD", StaleMonthly: 1 + 1 + 1, RemovedMonthly: 1, StaleAccumulated: 0.5 + 1}
+	if cost.Currency != want.Currency ||
+		math.Abs(cost.StaleMonthly-want.StaleMonthly) > 1e-9 ||
+		math.Abs(cost.RemovedMonthly-want.RemovedMonthly) > 1e-9 ||
+		math.Abs(cost.StaleAccumulated-want.StaleAccumulated) > 1e-9 {
+		t.Errorf("ComputeCost() = %+v, want %+v", cost, want)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"slices"
+	"sort"
+	"time"
+)
+
+// 差异状态
+// Diff statuses
+const (
+	// DiffNew 在新报告中新出现的过期文件
+	// DiffNew is a stale file that is new in the new report
+	DiffNew = "new"
+
+	// DiffGone 旧报告中的过期文件在新报告中已不存在，即已完成或已中止
+	// DiffGone is a stale file of the old report that is missing from the new report, i.e. completed or aborted
+	DiffGone = "gone"
+
+	// DiffStuck 在两份报告中都是过期文件
+	// DiffStuck is a file that is stale in both reports
+	DiffStuck = "stuck"
+)
+
+// DiffEntry 两份报告之间一个过期文件的差异
+// DiffEntry is the difference of one stale file between two reports
+type DiffEntry struct {
+	Status    string    `json:"status"`
+	Bucket    string    `json:"bucket"`
+	Key       string    `json:"key"`
+	Type      string    `json:"type"`
+	UploadID  string    `json:"upload_id,omitempty"`
+	VersionID string    `json:"version_id,omitempty"`
+	ModTime   time.Time `json:"mod_time"`
+	OldSize   int64     `json:"old_size"`
+	NewSize   int64     `json:"new_size"`
+}
+
+// Growth 返回文件在两份报告之间增长的大小
+// Growth returns how much the file grew between the two reports
+func (e DiffEntry) Growth() int64 {
+	return e.NewSize - e.OldSize
+}
+
+// BucketDiff 一个桶中过期文件的差异
+// BucketDiff is the difference of stale files in one bucket
+type BucketDiff struct {
+	Bucket string      `json:"bucket"`
+	New    []DiffEntry `json:"new"`
+	Gone   []DiffEntry `json:"gone"`
+	Stuck  []DiffEntry `json:"stuck"`
+}
+
+// fileIdentity 在报告之间识别同一个文件的键
+// fileIdentity is the key identifying the same file across reports
+type fileIdentity struct {
+	Bucket    string
+	Key       string
+	UploadID  string
+	VersionID string
+}
+
+// identityOf 返回文件的标识
+// identityOf returns the identity of a file
+func identityOf(file FileInfo) fileIdentity {
+	return fileIdentity{Bucket: file.Bucket, Key: file.Key, UploadID: file.UploadID, VersionID: file.VersionID}
+}
+
+// Diff 比较两份报告中的过期文件，按桶分组返回新出现的、已消失的和仍然存在的文件；
+// 只比较两份报告都扫描成功的桶，两份报告中都未过期的文件不参与比较
+// Diff compares the stale files of two reports and returns, grouped by bucket, the files that are new,
+// gone and still stuck; only buckets scanned successfully in both reports are compared,
+// and files that are not stale in either report are left out
+func Diff(oldResult, newResult *Result) []BucketDiff {
+	failed := map[string]bool{}
+	for _, bucket := range slices.Concat(oldResult.FailedBuckets, newResult.FailedBuckets) {
+		failed[bucket] = true
+	}
+	scanned := map[string]bool{}
+	for _, bucket := range oldResult.Buckets {
+		scanned[bucket] = slices.Contains(newResult.Buckets, bucket) && !failed[bucket]
+	}
+	compared := func(files []FileInfo) []FileInfo {
+		return slices.DeleteFunc(slices.Clone(files), func(file FileInfo) bool {
+			return !scanned[file.Bucket]
+		})
+	}
+	oldFiles := compared(oldResult.Files)
+	newFiles := compared(newResult.Files)
+
+	oldStale := map[fileIdentity]FileInfo{}
+	for _, file := range oldFiles {
+		if file.ShouldDelete {
+			oldStale[identityOf(file)] = file
+		}
+	}
+	newAll := map[fileIdentity]bool{}
+	for _, file := range newFiles {
+		newAll[identityOf(file)] = true
+	}
+
+	diffs := map[string]*BucketDiff{}
+	bucketDiff := func(bucket string) *BucketDiff {
+		if diffs[bucket] == nil {
+			diffs[bucket] = &BucketDiff{Bucket: bucket, New: []DiffEntry{}, Gone: []DiffEntry{}, Stuck: []DiffEntry{}}
+		}
+		return diffs[bucket]
+	}
+	entry := func(status string, file FileInfo) DiffEntry {
+		return DiffEntry{
+			Status:    status,
+			Bucket:    file.Bucket,
+			Key:       file.Key,
+			Type:      file.Type,
+			UploadID:  file.UploadID,
+			VersionID: file.VersionID,
+			ModTime:   file.ModTime,
+		}
+	}
+
+	for _, file := range newFiles {
+		if !file.ShouldDelete {
+			continue
+		}
+		d := bucketDiff(file.Bucket)
+		if old, ok := oldStale[identityOf(file)]; ok {
+			e := entry(DiffStuck, file)
+			e.OldSize = old.Size
+			e.NewSize = file.Size
+			d.Stuck = append(d.Stuck, e)
+		} else {
+			e := entry(DiffNew, file)
+			e.NewSize = file.Size
+			d.New = append(d.New, e)
+		}
+	}
+
+	for _, file := range oldFiles {
+		if !file.ShouldDelete || newAll[identityOf(file)] {
+			continue
+		}
+		e := entry(DiffGone, file)
+		e.OldSize = file.Size
+		d := bucketDiff(file.Bucket)
+		d.Gone = append(d.Gone, e)
+	}
+
+	// 按桶名排序，仍然存在的文件按增长从大到小排列
+	// Sort by bucket name, and stuck files by growth from largest to smallest
+	result := make([]BucketDiff, 0, len(diffs))
+	for _, d := range diffs {
+		sort.SliceStable(d.Stuck, func(i, j int) bool {
+			return d.Stuck[i].Growth() > d.Stuck[j].Growth()
+		})
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Bucket < result[j].Bucket
+	})
+	return result
+}
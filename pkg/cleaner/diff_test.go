@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	upload := func(bucket, key string, size int64, stale bool) FileInfo {
+		return FileInfo{Bucket: bucket, Key: key, UploadID: "id-" + key, Size: size, Type: FileTypeUpload, ShouldDelete: stale}
+	}
+	keys := func(entries []DiffEntry) []string {
+		var keys []string
+		for _, e := range entries {
+			keys = append(keys, e.Key)
+		}
+		return keys
+	}
+	type bucketKeys struct {
+		bucket           string
+		new, gone, stuck []string
+	}
+
+	tests := []struct {
+		name                 string
+		oldResult, newResult *Result
+		want                 []bucketKeys
+	}{
+		{
+			name: "new, gone and stuck files",
+			oldResult: &Result{Buckets: []string{"a"}, Files: []FileInfo{
+				upload("a", "stuck", 100, true),
+				upload("a", "gone", 100, true),
+				upload("a", "fresh-then", 100, false),
+			}},
+			newResult: &Result{Buckets: []string{"a"}, Files: []FileInfo{
+				upload("a", "stuck", 300, true),
+				upload("a", "fresh-then", 200, true),
+				upload("a", "fresh", 100, false),
+			}},
+			want: []bucketKeys{{bucket: "a", new: []string{"fresh-then"}, gone: []string{"gone"}, stuck: []string{"stuck"}}},
+		},
+		{
+			name:      "stale file that is no longer stale is not gone",
+			oldResult: &Result{Buckets: []string{"a"}, Files: []FileInfo{upload("a", "x", 100, true)}},
+			newResult: &Result{Buckets: []string{"a"}, Files: []FileInfo{upload("a", "x", 100, false)}},
+			want:      []bucketKeys{},
+		},
+		{
+			name:      "failed buckets are not compared",
+			oldResult: &Result{Buckets: []string{"a", "b"}, Files: []FileInfo{upload("a", "x", 100, true), upload("b", "y", 100, true)}},
+			newResult: &Result{Buckets: []string{"b"}, FailedBuckets: []string{"a"}, Files: []FileInfo{upload("b", "y", 100, true)}},
+			want:      []bucketKeys{{bucket: "b", stuck: []string{"y"}}},
+		},
+		{
+			name:      "buckets scanned in only one report are not compared",
+			oldResult: &Result{Buckets: []string{"a", "b"}, Files: []FileInfo{upload("a", "x", 100, true), upload("b", "y", 100, true)}},
+			newResult: &Result{Buckets: []string{"b", "c"}, Files: []FileInfo{upload("b", "z", 100, true), upload("c", "w", 100, true)}},
+			want:      []bucketKeys{{bucket: "b", new: []string{"z"}, gone: []string{"y"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffs := Diff(tt.oldResult, tt.newResult)
+			if len(diffs) != len(tt.want) {
+				t.Fatalf("Diff() = %+v, want %d buckets", diffs, len(tt.want))
+			}
+			for i, want := range tt.want {
+				d := diffs[i]
+				if d.Bucket != want.bucket || !slices.Equal(keys(d.New), want.new) ||
+					!slices.Equal(keys(d.Gone), want.gone) || !slices.Equal(keys(d.Stuck), want.stuck) {
+					t.Errorf("bucket %d = %s new %v gone %v stuck %v, want %+v", i, d.Bucket, keys(d.New), keys(d.Gone), keys(d.Stuck), want)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffStuckGrowth(t *testing.T) {
+	file := func(key string, size int64) FileInfo {
+		return FileInfo{Bucket: "a", Key: key, Size: size, ShouldDelete: true}
+	}
+	oldResult := &Result{Buckets: []string{"a"}, Files: []FileInfo{file("small", 100), file("large", 100), file("shrunk", 500)}}
+	newResult := &Result{Buckets: []string{"a"}, Files: []FileInfo{file("small", 150), file("large", 900), file("shrunk", 400)}}
+
+	diffs := Diff(oldResult, newResult)
+	if len(diffs) != 1 {
+		t.Fatalf("Diff() = %+v, want one bucket", diffs)
+	}
+	var growth []int64
+	for _, e := range diffs[0].Stuck {
+		growth = append(growth, e.Growth())
+	}
+	if want := []int64{800, 50, -100}; !slices.Equal(growth, want) {
+		t.Errorf("stuck growth = %v, want %v", growth, want)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupSummary 分组汇总信息
+// GroupSummary contains the aggregated statistics of one group
+type GroupSummary struct {
+	Group string `json:"group"`
+	Statistics
+}
+
+// groupKeyFunc 计算文件所属分组的函数
+// groupKeyFunc calculates the group a file belongs to
+type groupKeyFunc func(file FileInfo, now time.Time) string
+
+// ageBuckets 按年龄分组时使用的区间，按上限从小到大排列
+// ageBuckets are the ranges used when grouping by age, ordered by upper bound
+var ageBuckets = []struct {
+	Name  string
+	Upper time.Duration
+}{
+	{"<1d", 24 * time.Hour},
+	{"1d-7d", 7 * 24 * time.Hour},
+	{"7d-30d", 30 * 24 * time.Hour},
+	{"30d-90d", 90 * 24 * time.Hour},
+	{">=90d", 0},
+}
+
+// parseGroupBy 解析分组方式：bucket, prefix:N, initiator, ageBucket
+// parseGroupBy parses the group by spec: bucket, prefix:N, initiator, ageBucket
+func parseGroupBy(spec string) (groupKeyFunc, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch {
+	case spec == "":
+		return nil, nil
+	case name == "bucket" && !hasArg:
+		return func(file FileInfo, now time.Time) string {
+			return file.Bucket
+		}, nil
+	case name == "prefix":
+		depth, err := strconv.Atoi(arg)
+		if err != nil || depth < 1 {
+			return nil, fmt.Errorf("无效的前缀深度 '%s'，应为正整数，如 'prefix:1'\nInvalid prefix depth '%s', must be a positive integer, e.g. 'prefix:1'", arg, arg)
+		}
+		return func(file FileInfo, now time.Time) string {
+			return file.Bucket + "/" + keyPrefix(file.Key, depth)
+		}, nil
+	case name == "initiator" && !hasArg:
+		return func(file FileInfo, now time.Time) string {
+			if file.Initiator == "" {
+				return "(unknown)"
+			}
+			return file.Initiator
+		}, nil
+	case name == "ageBucket" && !hasArg:
+		return func(file FileInfo, now time.Time) string {
+			age := now.Sub(file.AgeTime())
+			for _, bucket := range ageBuckets {
+				if bucket.Upper == 0 || age < bucket.Upper {
+					return bucket.Name
+				}
+			}
+			return ageBuckets[len(ageBuckets)-1].Name
+		}, nil
+	default:
+		return nil, fmt.Errorf("无效的分组方式 '%s'，有效选项为: bucket, prefix:N, initiator, ageBucket\nInvalid group by '%s', valid options are: bucket, prefix:N, initiator, ageBucket", spec, spec)
+	}
+}
+
+// keyPrefix 返回键的前 depth 级目录，不足时返回整个目录部分
+// keyPrefix returns the first depth directory levels of the key, or the whole directory part if shorter
+func keyPrefix(key string, depth int) string {
+	parts := strings.Split(key, "/")
+	// 最后一段是文件名，不属于目录
+	// The last part is the file name and is not a directory
+	if len(parts)-1 < depth {
+		depth = len(parts) - 1
+	}
+	if depth == 0 {
+		return ""
+	}
+	return strings.Join(parts[:depth], "/") + "/"
+}
+
+// GroupFiles 按分组方式汇总文件，结果按容量从大到小排列，spec 与 Options.GroupBy 相同
+// GroupFiles summarizes files by the group by spec, ordered by size from largest to smallest;
+// spec is the same as Options.GroupBy
+func GroupFiles(files []FileInfo, spec string, now time.Time) ([]GroupSummary, error) {
+	key, err := parseGroupBy(spec)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return groupFiles(files, key, now), nil
+}
+
+// groupFiles 按分组汇总文件，结果按容量从大到小排列
+// groupFiles summarizes files by group, ordered by size from largest to smallest
+func groupFiles(files []FileInfo, key groupKeyFunc, now time.Time) []GroupSummary {
+	filesByGroup := map[string][]FileInfo{}
+	for _, file := range files {
+		group := key(file, now)
+		filesByGroup[group] = append(filesByGroup[group], file)
+	}
+
+	groups := make([]GroupSummary, 0, len(filesByGroup))
+	for group, groupFiles := range filesByGroup {
+		groups = append(groups, GroupSummary{
+			Group:      group,
+			Statistics: ComputeStatistics(groupFiles),
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].TotalSize != groups[j].TotalSize {
+			return groups[i].TotalSize > groups[j].TotalSize
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups
+}
@@ -0,0 +1,282 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// lifecycleRuleIDPrefix 由本工具创建的 lifecycle 规则ID前缀
+// lifecycleRuleIDPrefix is the ID prefix of lifecycle rules created by this tool
+const lifecycleRuleIDPrefix = "s4-cleaner-abort-incomplete-multipart-upload"
+
+// LifecycleInfo 桶中清理未完成分段上传的 lifecycle 规则信息
+// LifecycleInfo describes a bucket lifecycle rule that aborts incomplete multipart uploads
+type LifecycleInfo struct {
+	Bucket string `json:"bucket"`
+	RuleID string `json:"rule_id"`
+	Status string `json:"status"`
+	Prefix string `json:"prefix"`
+	Days   int32  `json:"days_after_initiation"`
+}
+
+// LifecycleChange 一个桶的 lifecycle 规则修改，规则以单行描述表示
+// LifecycleChange is the lifecycle rule change of one bucket, with rules given as one-line descriptions
+type LifecycleChange struct {
+	Bucket  string   `json:"bucket"`
+	Before  []string `json:"before"`
+	After   []string `json:"after"`
+	Changed bool     `json:"changed"`
+	Applied bool     `json:"applied"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// LifecycleRules 列出每个桶的 AbortIncompleteMultipartUpload 规则，无法读取的桶记录在错误列表中
+// LifecycleRules lists the AbortIncompleteMultipartUpload rules of each bucket, recording unreadable buckets in the error list
+func (c *S3Cleaner) LifecycleRules(ctx context.Context) ([]LifecycleInfo, []BucketError, error) {
+	buckets, err := c.targetBuckets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	infos := []LifecycleInfo{}
+	var bucketErrors []BucketError
+	for _, bucket := range buckets {
+		rules, err := c.getLifecycleRules(ctx, bucket)
+		if err != nil {
+			c.log.Error("获取 lifecycle 规则失败 | Failed to get lifecycle rules", "bucket", bucket, "error", err)
+			bucketErrors = append(bucketErrors, BucketError{Bucket: bucket, Error: err.Error()})
+			continue
+		}
+
+		for _, rule := range rules {
+			if rule.AbortIncompleteMultipartUpload == nil {
+				continue
+			}
+			prefix, _ := rulePrefix(rule)
+			infos = append(infos, LifecycleInfo{
+				Bucket: bucket,
+				RuleID: aws.ToString(rule.ID),
+				Status: string(rule.Status),
+				Prefix: prefix,
+				Days:   aws.ToInt32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation),
+			})
+		}
+	}
+
+	return infos, bucketErrors, nil
+}
+
+// SetLifecycle 为前缀添加或更新 AbortIncompleteMultipartUpload 规则，保留桶中已有的其他规则；dryRun 时只返回修改
+// SetLifecycle adds or updates the AbortIncompleteMultipartUpload rule of the prefix, keeping other rules of the bucket;
+// only the changes are returned when dryRun is true
+func (c *S3Cleaner) SetLifecycle(ctx context.Context, prefix string, days int, dryRun bool) ([]LifecycleChange, error) {
+	if days < 1 {
+		return nil, fmt.Errorf("lifecycle 规则的天数至少为1天\nLifecycle rule requires at least 1 day")
+	}
+
+	return c.updateLifecycle(ctx, dryRun, func(rules []types.LifecycleRule) []types.LifecycleRule {
+		for i, rule := range rules {
+			if rp, ok := rulePrefix(rule); ok && rp == prefix && rule.AbortIncompleteMultipartUpload != nil {
+				updated := rule
+				updated.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{DaysAfterInitiation: aws.Int32(int32(days))}
+				rules[i] = updated
+				return rules
+			}
+		}
+
+		ruleID := lifecycleRuleIDPrefix
+		if prefix != "" {
+			ruleID += "-" + strings.Trim(prefix, "/")
+		}
+		return append(rules, types.LifecycleRule{
+			ID:     aws.String(ruleID),
+			Status: types.ExpirationStatusEnabled,
+			Filter: &types.LifecycleRuleFilterMemberPrefix{Value: prefix},
+			AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int32(int32(days)),
+			},
+		})
+	})
+}
+
+// RemoveLifecycle 删除前缀对应的 AbortIncompleteMultipartUpload 规则，规则中的其他动作保持不变；dryRun 时只返回修改
+// RemoveLifecycle removes the AbortIncompleteMultipartUpload rule of the prefix, keeping other actions of the rule;
+// only the changes are returned when dryRun is true
+func (c *S3Cleaner) RemoveLifecycle(ctx context.Context, prefix string, dryRun bool) ([]LifecycleChange, error) {
+	return c.updateLifecycle(ctx, dryRun, func(rules []types.LifecycleRule) []types.LifecycleRule {
+		kept := make([]types.LifecycleRule, 0, len(rules))
+		for _, rule := range rules {
+			if rp, ok := rulePrefix(rule); ok && rp == prefix && rule.AbortIncompleteMultipartUpload != nil {
+				rule.AbortIncompleteMultipartUpload = nil
+				if !hasLifecycleAction(rule) {
+					continue
+				}
+			}
+			kept = append(kept, rule)
+		}
+		return kept
+	})
+}
+
+// updateLifecycle 对每个桶的 lifecycle 规则进行修改，非 dryRun 时写回有变化的桶
+// updateLifecycle modifies the lifecycle rules of each bucket and writes back changed buckets unless dryRun is true
+func (c *S3Cleaner) updateLifecycle(ctx context.Context, dryRun bool, modify func([]types.LifecycleRule) []types.LifecycleRule) ([]LifecycleChange, error) {
+	buckets, err := c.targetBuckets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]LifecycleChange, 0, len(buckets))
+	for _, bucket := range buckets {
+		change := LifecycleChange{Bucket: bucket}
+		oldRules, err := c.getLifecycleRules(ctx, bucket)
+		if err != nil {
+			c.log.Error("获取 lifecycle 规则失败 | Failed to get lifecycle rules", "bucket", bucket, "error", err)
+			change.Error = err.Error()
+			changes = append(changes, change)
+			continue
+		}
+
+		newRules := modify(append([]types.LifecycleRule{}, oldRules...))
+		change.Before = describeRules(oldRules)
+		change.After = describeRules(newRules)
+		change.Changed = strings.Join(change.Before, "\n") != strings.Join(change.After, "\n")
+		if change.Changed && !dryRun {
+			if err := c.putLifecycleRules(ctx, bucket, newRules); err != nil {
+				c.log.Error("写入 lifecycle 规则失败 | Failed to put lifecycle rules", "bucket", bucket, "error", err)
+				change.Error = err.Error()
+			} else {
+				c.log.Info("已更新 lifecycle 规则 | Updated lifecycle rules", "bucket", bucket)
+				change.Applied = true
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// getLifecycleRules 获取桶的 lifecycle 规则，没有配置时返回空列表
+// getLifecycleRules gets the lifecycle rules of the bucket, returning an empty list if none is configured
+func (c *S3Cleaner) getLifecycleRules(ctx context.Context, bucket string) ([]types.LifecycleRule, error) {
+	resp, err := c.client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(bucket),
+	})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "NoSuchLifecycleConfiguration" {
+			return []types.LifecycleRule{}, nil
+		}
+		return nil, fmt.Errorf("无法获取桶 %s 的 lifecycle 规则: %v\nFailed to get lifecycle rules of bucket %s: %v", bucket, err, bucket, err)
+	}
+	return resp.Rules, nil
+}
+
+// putLifecycleRules 写入桶的 lifecycle 规则，规则为空时删除 lifecycle 配置
+// putLifecycleRules writes the lifecycle rules of the bucket, deleting the lifecycle configuration when there are no rules
+func (c *S3Cleaner) putLifecycleRules(ctx context.Context, bucket string, rules []types.LifecycleRule) error {
+	if len(rules) == 0 {
+		_, err := c.client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(bucket),
+		})
+		if err != nil {
+			return fmt.Errorf("无法删除桶 %s 的 lifecycle 配置: %v\nFailed to delete lifecycle configuration of bucket %s: %v", bucket, err, bucket, err)
+		}
+		return nil
+	}
+
+	_, err := c.client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(bucket),
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{Rules: rules},
+	})
+	if err != nil {
+		return fmt.Errorf("无法写入桶 %s 的 lifecycle 规则: %v\nFailed to put lifecycle rules of bucket %s: %v", bucket, err, bucket, err)
+	}
+	return nil
+}
+
+// rulePrefix 返回规则的前缀过滤；规则使用标签或大小等其他过滤条件时返回 false
+// rulePrefix returns the prefix filter of the rule; returns false if the rule filters on tags, size or other conditions
+func rulePrefix(rule types.LifecycleRule) (string, bool) {
+	switch filter := rule.Filter.(type) {
+	case nil:
+		return aws.ToString(rule.Prefix), true
+	case *types.LifecycleRuleFilterMemberPrefix:
+		return filter.Value, true
+	default:
+		return "", false
+	}
+}
+
+// hasLifecycleAction 检查规则是否还包含任何动作
+// hasLifecycleAction checks whether the rule still contains any action
+func hasLifecycleAction(rule types.LifecycleRule) bool {
+	return rule.AbortIncompleteMultipartUpload != nil ||
+		rule.Expiration != nil ||
+		len(rule.Transitions) > 0 ||
+		rule.NoncurrentVersionExpiration != nil ||
+		len(rule.NoncurrentVersionTransitions) > 0
+}
+
+// describeRule 返回规则的单行描述，用于显示差异
+// describeRule returns a one-line description of the rule for diff output
+func describeRule(rule types.LifecycleRule) string {
+	parts := []string{
+		"id=" + aws.ToString(rule.ID),
+		"status=" + string(rule.Status),
+	}
+
+	if prefix, ok := rulePrefix(rule); ok {
+		parts = append(parts, fmt.Sprintf("prefix=%q", prefix))
+	} else {
+		parts = append(parts, "filter=(complex)")
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		parts = append(parts, fmt.Sprintf("abortIncompleteMultipartUpload=%dd", aws.ToInt32(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)))
+	}
+	if rule.Expiration != nil {
+		switch {
+		case rule.Expiration.Days != nil:
+			parts = append(parts, fmt.Sprintf("expiration=%dd", aws.ToInt32(rule.Expiration.Days)))
+		case rule.Expiration.Date != nil:
+			parts = append(parts, "expiration="+rule.Expiration.Date.Format("2006-01-02"))
+		case aws.ToBool(rule.Expiration.ExpiredObjectDeleteMarker):
+			parts = append(parts, "expiredObjectDeleteMarker=true")
+		}
+	}
+	if len(rule.Transitions) > 0 {
+		parts = append(parts, fmt.Sprintf("transitions=%d", len(rule.Transitions)))
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		parts = append(parts, fmt.Sprintf("noncurrentVersionExpiration=%dd", aws.ToInt32(rule.NoncurrentVersionExpiration.NoncurrentDays)))
+	}
+	if len(rule.NoncurrentVersionTransitions) > 0 {
+		parts = append(parts, fmt.Sprintf("noncurrentVersionTransitions=%d", len(rule.NoncurrentVersionTransitions)))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// describeRules 返回每条规则的单行描述
+// describeRules returns a one-line description of each rule
+func describeRules(rules []types.LifecycleRule) []string {
+	lines := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		lines = append(lines, describeRule(rule))
+	}
+	return lines
+}
@@ -0,0 +1,220 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// deleteObjectsBatchSize DeleteObjects 单次请求最多删除的对象数
+// deleteObjectsBatchSize is the maximum number of objects in one DeleteObjects request
+const deleteObjectsBatchSize = 1000
+
+// tempPresets 常见临时文件的预设模式
+// tempPresets are preset patterns of common temporary files
+var tempPresets = map[string][]string{
+	// Spark/Hadoop FileOutputCommitter 的临时目录
+	// Temporary directory of the Spark/Hadoop FileOutputCommitter
+	"spark": {"_temporary/"},
+	// Hadoop S3A magic committer 的临时目录
+	// Temporary directory of the Hadoop S3A magic committer
+	"s3a":  {"__magic/", "__magic_job-*/"},
+	"tmp":  {"*.tmp"},
+	"part": {"*.part"},
+	// rclone 传输中的部分文件
+	// Partial files of rclone transfers in progress
+	"rclone": {"*.partial"},
+}
+
+// resolvePatterns 将预设和自定义模式合并为模式列表，两者都未指定时使用全部预设
+// resolvePatterns merges presets and custom patterns into a pattern list, using all presets when neither is specified
+func resolvePatterns(presets, custom []string) ([]string, error) {
+	if len(presets) == 0 && len(custom) == 0 {
+		for name := range tempPresets {
+			presets = append(presets, name)
+		}
+		sort.Strings(presets)
+	}
+
+	var patterns []string
+	for _, name := range presets {
+		preset, ok := tempPresets[name]
+		if !ok {
+			return nil, fmt.Errorf("无效的预设 '%s'，有效选项为: spark, s3a, tmp, part, rclone\nInvalid preset '%s', valid options are: spark, s3a, tmp, part, rclone", name, name)
+		}
+		patterns = append(patterns, preset...)
+	}
+
+	for _, pattern := range custom {
+		if _, err := path.Match(strings.TrimSuffix(pattern, "/"), ""); err != nil {
+			return nil, fmt.Errorf("无效的模式 '%s': %v\nInvalid pattern '%s': %v", pattern, err, pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// matchPattern 检查键是否匹配模式：
+// 以 / 结尾的模式匹配键中任意一级目录名，包含 / 的模式匹配整个键，其他模式匹配文件名
+// matchPattern checks whether the key matches the pattern:
+// patterns ending with / match any directory name in the key, patterns containing / match the whole key,
+// other patterns match the file name
+func matchPattern(pattern, key string) bool {
+	if dir, ok := strings.CutSuffix(pattern, "/"); ok {
+		segments := strings.Split(key, "/")
+		for _, segment := range segments[:len(segments)-1] {
+			if matched, _ := path.Match(dir, segment); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	if strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, key)
+		return matched
+	}
+
+	matched, _ := path.Match(pattern, path.Base(key))
+	return matched
+}
+
+// matchAnyPattern 检查键是否匹配任意一个模式
+// matchAnyPattern checks whether the key matches any of the patterns
+func (c *S3Cleaner) matchAnyPattern(key string) bool {
+	for _, pattern := range c.patterns {
+		if matchPattern(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// processObjectsInBucket 处理一个桶中匹配临时文件模式的对象，并按运行状态逐页删除
+// processObjectsInBucket processes objects matching temporary file patterns in one bucket
+// and deletes them page by page as the run requires
+func (c *S3Cleaner) processObjectsInBucket(ctx context.Context, r *run, bucket string) ([]FileInfo, error) {
+	// 从检查点记录的键之后继续，续传令牌不保存在检查点中
+	// Continue after the key recorded in the checkpoint, continuation tokens are not saved in the checkpoint
+	startAfter := optionalString(r.cp.markers(bucket).StartAfter)
+	var continuationToken *string
+	files := []FileInfo{}
+	page := 0
+
+	// 分页列出所有对象
+	// List all objects with pagination
+	for {
+		resp, err := c.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:            aws.String(bucket),
+			StartAfter:        startAfter,
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("无法列出桶 %s 中的对象: %v\nFailed to list objects in bucket %s: %v", bucket, err, bucket, err)
+		}
+
+		// 处理当前页中匹配的对象
+		// Process matching objects in current page
+		page++
+		c.log.Debug("已列出一页对象 | Listed a page of objects", "bucket", bucket, "page", page, "objects", len(resp.Contents))
+		pageStart := len(files)
+		for _, object := range resp.Contents {
+			key := aws.ToString(object.Key)
+			if !c.matchAnyPattern(key) {
+				continue
+			}
+
+			file := FileInfo{
+				Bucket:       bucket,
+				Key:          key,
+				Size:         aws.ToInt64(object.Size),
+				ModTime:      aws.ToTime(object.LastModified),
+				Type:         FileTypeObject,
+				StorageClass: string(object.StorageClass),
+			}
+			file.ShouldDelete = c.stale(r, &file, file.ModTime)
+			if c.keepLogged(r, file) {
+				files = append(files, file)
+			}
+		}
+
+		// 删除当前页中需要删除的对象
+		// Delete objects in current page that should be deleted
+		r.progress.addPage(files[pageStart:])
+		c.deleteFiles(ctx, r, files[pageStart:])
+
+		// 如果没有更多页，则退出循环
+		// If no more pages, exit loop
+		if resp.IsTruncated == nil || !*resp.IsTruncated {
+			break
+		}
+		continuationToken = resp.NextContinuationToken
+
+		// 当前页已处理完成，记录最后一个键
+		// The current page is finished, record its last key
+		if len(resp.Contents) > 0 {
+			if err := r.cp.savePage(bucket, checkpointMarkers{StartAfter: aws.ToString(resp.Contents[len(resp.Contents)-1].Key)}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// deleteObjects 使用 DeleteObjects 批量删除同一个桶中的对象或对象版本，并记录每个对象的删除结果
+// deleteObjects deletes objects or object versions of one bucket in batches with DeleteObjects and records the result of each object
+func (c *S3Cleaner) deleteObjects(ctx context.Context, bucket string, files []*FileInfo) {
+	for start := 0; start < len(files); start += deleteObjectsBatchSize {
+		batch := files[start:min(start+deleteObjectsBatchSize, len(files))]
+
+		identifiers := make([]types.ObjectIdentifier, 0, len(batch))
+		for _, file := range batch {
+			identifier := types.ObjectIdentifier{Key: aws.String(file.Key)}
+			if file.VersionID != "" {
+				identifier.VersionId = aws.String(file.VersionID)
+			}
+			identifiers = append(identifiers, identifier)
+		}
+
+		resp, err := c.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{
+				Objects: identifiers,
+				Quiet:   aws.Bool(true),
+			},
+		})
+
+		// 安静模式下只返回删除失败的对象
+		// Only objects that failed to delete are returned in quiet mode
+		failed := map[string]bool{}
+		if err != nil {
+			c.log.Warn("批量删除对象失败 | DeleteObjects failed", "bucket", bucket, "objects", len(batch), "error", err)
+		} else {
+			for _, deleteErr := range resp.Errors {
+				c.log.Warn("删除对象失败 | Failed to delete object", "bucket", bucket, "key", aws.ToString(deleteErr.Key),
+					"version_id", aws.ToString(deleteErr.VersionId), "code", aws.ToString(deleteErr.Code), "error", aws.ToString(deleteErr.Message))
+				failed[aws.ToString(deleteErr.Key)+"\x00"+aws.ToString(deleteErr.VersionId)] = true
+			}
+		}
+
+		for _, file := range batch {
+			success := err == nil && !failed[file.Key+"\x00"+file.VersionID]
+			file.DeleteSuccess = &success
+		}
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPresetPatterns(t *testing.T) {
+	tests := []struct {
+		preset string
+		key    string
+		want   bool
+	}{
+		{"spark", "out/_temporary/0/part-00000", true},
+		{"spark", "_temporary/attempt/file", true},
+		{"spark", "out/not_temporary/part-00000", false},
+		{"spark", "out/_temporary", false},
+		{"s3a", "table/__magic/job-1/file.parquet", true},
+		{"s3a", "table/__magic_job-42/file.parquet", true},
+		{"s3a", "table/magic/file.parquet", false},
+		{"tmp", "data/upload.tmp", true},
+		{"tmp", "data.tmp/file.bin", false},
+		{"tmp", "data/upload.tmpx", false},
+		{"part", "downloads/video.mp4.part", true},
+		{"part", "downloads/part-00000", false},
+		{"rclone", "backup/file.bin.partial", true},
+		{"rclone", "backup/partial/file.bin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset+" "+tt.key, func(t *testing.T) {
+			patterns, err := resolvePatterns([]string{tt.preset}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := &S3Cleaner{patterns: patterns}
+			if got := c.matchAnyPattern(tt.key); got != tt.want {
+				t.Errorf("matchAnyPattern(%q) with %v = %v, want %v", tt.key, patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		// 匹配文件名 | Matching the file name
+		{"*.bak", "db/dump.bak", true},
+		{"*.bak", "dump.bak", true},
+		{"*.bak", "db.bak/dump.sql", false},
+		{"upload-?.bin", "a/upload-1.bin", true},
+		{"upload-?.bin", "a/upload-10.bin", false},
+		// 匹配任意一级目录名 | Matching any directory name
+		{".staging-*/", "a/.staging-123/b/file", true},
+		{".staging-*/", "a/b/.staging-123", false},
+		// 匹配整个键 | Matching the whole key
+		{"tmp/*.bin", "tmp/file.bin", true},
+		{"tmp/*.bin", "a/tmp/file.bin", false},
+		{"tmp/*.bin", "tmp/sub/file.bin", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			if got := matchPattern(tt.pattern, tt.key); got != tt.want {
+				t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePatterns(t *testing.T) {
+	all, err := resolvePatterns(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 6 {
+		t.Errorf("resolvePatterns() = %v, want all preset patterns", all)
+	}
+
+	custom, err := resolvePatterns(nil, []string{"*.bak"})
+	if err != nil || len(custom) != 1 || custom[0] != "*.bak" {
+		t.Errorf("resolvePatterns(custom) = %v, %v, want only the custom pattern", custom, err)
+	}
+
+	if _, err := resolvePatterns([]string{"hive"}, nil); err == nil || !strings.Contains(err.Error(), "Invalid preset 'hive'") {
+		t.Errorf("resolvePatterns(hive) error = %v, want an invalid preset error", err)
+	}
+	if _, err := resolvePatterns(nil, []string{"[.bak/"}); err == nil || !strings.Contains(err.Error(), "Invalid pattern '[.bak/'") {
+		t.Errorf("resolvePatterns([.bak/) error = %v, want an invalid pattern error", err)
+	}
+}
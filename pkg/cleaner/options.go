@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Options 清理器的选项，建议从 DefaultOptions 开始修改
+// Options configures the cleaner, it is recommended to start from DefaultOptions
+type Options struct {
+	// AccessKey 和 SecretKey 用于创建S3客户端，都为空时使用 AWS 默认凭证链，指定 Client 时忽略
+	// AccessKey and SecretKey are used to create the S3 client, the default AWS credential chain is used
+	// when both are empty, ignored when Client is set
+	AccessKey string
+	SecretKey string
+
+	// Client 使用已有的S3客户端（如 *s3.Client），此时重试和超时选项不生效
+	// Client uses an existing S3 client such as *s3.Client, in which case the retry and timeout options have no effect
+	Client S3API
+
+	// Buckets 存储桶名称或 glob 模式（如 logs-*），为空表示所有桶
+	// Bucket names or glob patterns (e.g. logs-*), empty means all buckets
+	Buckets []string
+
+	// ExcludeBuckets 排除的存储桶名称或 glob 模式
+	// Bucket names or glob patterns to exclude
+	ExcludeBuckets []string
+
+	// OlderThan 只清理早于此时长的文件
+	// Only clean files older than this duration
+	OlderThan time.Duration
+
+	// AgeBasis uploads 模式下判断年龄的依据：AgeBasisInitiated（默认）, AgeBasisLastPart
+	// What the age of multipart uploads is judged by: AgeBasisInitiated (default), AgeBasisLastPart
+	AgeBasis string
+
+	// Rules 按顺序匹配的策略规则，匹配的规则决定文件是否保留以及使用的时长，没有匹配规则的文件使用 OlderThan
+	// Policy rules matched in order, the matching rule decides whether a file is kept and which duration applies,
+	// files matching no rule use OlderThan
+	Rules []Rule
+
+	// Target 清理目标：TargetUploads（默认）, TargetObjects, TargetVersions
+	// Cleaning target: TargetUploads (default), TargetObjects, TargetVersions
+	Target string
+
+	// Presets 和 Patterns objects 模式下使用的临时文件预设和自定义模式，都为空时使用全部预设
+	// Presets and Patterns are the temporary file presets and custom patterns in objects mode,
+	// all presets are used when both are empty
+	Presets  []string
+	Patterns []string
+
+	// KeepVersions versions 模式下每个对象保留的最新非当前版本数
+	// Number of newest noncurrent versions to keep per object in versions mode
+	KeepVersions int
+
+	// SortBy 结果的排序方式：size, age, key, bucket，也决定删除的先后顺序
+	// Sort order of the result: size, age, key, bucket, which also decides the deletion order
+	SortBy  string
+	Reverse bool
+
+	// MaxDeleteCount 和 MaxDeleteBytes 一次运行最多删除的文件数和容量，0 表示不限制
+	// Maximum number and size of files to delete in one run, 0 means unlimited
+	MaxDeleteCount int
+	MaxDeleteBytes int64
+
+	// GroupBy 结果的分组方式：bucket, prefix:N, initiator, ageBucket，为空表示不分组
+	// How to group the result: bucket, prefix:N, initiator, ageBucket, empty means no grouping
+	GroupBy string
+
+	// Pricing 估算存储成本使用的价格，为 nil 时使用 DefaultPricing
+	// Pricing used to estimate storage costs, DefaultPricing is used when nil
+	Pricing *Pricing
+
+	// Checkpoint 记录扫描进度的检查点文件，为空表示不使用
+	// Checkpoint file recording the scan progress, empty means disabled
+	Checkpoint string
+
+	// MaxRetries 每个S3请求在首次尝试之外的最大重试次数
+	// Maximum number of retries of each S3 request after the first attempt
+	MaxRetries int
+
+	// RetryMode 重试模式：RetryModeStandard（默认）, RetryModeAdaptive
+	// Retry mode: RetryModeStandard (default), RetryModeAdaptive
+	RetryMode string
+
+	// RequestTimeout 和 ConnectTimeout 每次请求尝试和建立连接的超时时间，0 表示不限制
+	// Timeouts of each request attempt and of establishing a connection, 0 means no timeout
+	RequestTimeout time.Duration
+	ConnectTimeout time.Duration
+
+	// Logger 记录桶的处理过程、失败的API调用和每个文件的判断，为 nil 时不记录日志
+	// Logger records bucket processing, failed API calls and the decision for each file, nil disables logging
+	Logger *slog.Logger
+
+	// Initiators 和 ExcludeInitiators uploads 模式下只包含或排除这些发起者的上传，按名称或ID匹配
+	// Only include or exclude uploads of these initiators in uploads mode, matched by display name or ID
+	Initiators        []string
+	ExcludeInitiators []string
+
+	// Owners 和 ExcludeOwners uploads 模式下只包含或排除这些所有者的上传，按名称或ID匹配
+	// Only include or exclude uploads of these owners in uploads mode, matched by display name or ID
+	Owners        []string
+	ExcludeOwners []string
+
+	// Where 过滤表达式，如 size > 1GiB && age > 3d && key matches "^tmp/"，不满足的文件不出现在结果中，也不会被删除
+	// Filter expression such as size > 1GiB && age > 3d && key matches "^tmp/", files that do not satisfy it
+	// are left out of the result and never deleted
+	Where string
+
+	// Filter 过滤扫描到的文件，返回 false 的文件不出现在结果中，也不会被删除
+	// Filter filters scanned files, files for which it returns false are left out of the result and never deleted
+	Filter func(file FileInfo) bool
+
+	// OnProgress 在扫描和删除进度变化时调用
+	// OnProgress is called whenever the scan or delete progress changes
+	OnProgress func(event ProgressEvent)
+
+	// OnDelete 在每个文件的删除尝试完成后调用
+	// OnDelete is called after the delete attempt of each file
+	OnDelete func(file FileInfo)
+}
+
+// DefaultOptions 返回与命令行默认值一致的选项
+// DefaultOptions returns options matching the command line defaults
+func DefaultOptions() Options {
+	return Options{
+		OlderThan:      7 * 24 * time.Hour,
+		Target:         TargetUploads,
+		MaxRetries:     3,
+		RetryMode:      RetryModeStandard,
+		RequestTimeout: 2 * time.Minute,
+		ConnectTimeout: 10 * time.Second,
+	}
+}
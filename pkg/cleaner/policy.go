@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 规则动作
+// Rule actions
+const (
+	// RuleActionKeep 保留匹配的文件，从不删除
+	// RuleActionKeep keeps matching files and never deletes them
+	RuleActionKeep = "keep"
+
+	// RuleActionAbort 匹配的文件早于规则的时长时中止上传或删除
+	// RuleActionAbort aborts or deletes matching files older than the rule's duration
+	RuleActionAbort = "abort"
+)
+
+// Rule 策略中的一条规则，所有设置的条件都满足时匹配；规则按顺序匹配，第一条匹配的规则生效，
+// 没有匹配任何规则的文件使用 OlderThan 判断
+// Rule is one rule of a policy, matching when all conditions that are set hold; rules are matched in order
+// and the first matching rule applies, files matching no rule are judged by OlderThan
+type Rule struct {
+	// Name 规则名称，记录在匹配的文件上，为空时使用规则的序号，如 #1
+	// Rule name, recorded on matching files, the rule's position such as #1 is used when empty
+	Name string
+
+	// Bucket 桶名称或 glob 模式
+	// Bucket name or glob pattern
+	Bucket string
+
+	// Prefix 键的前缀
+	// Key prefix
+	Prefix string
+
+	// Regex 键需要匹配的正则表达式
+	// Regular expression the key must match
+	Regex string
+
+	// MinSize 和 MaxSize 文件大小的范围（包含边界），0 表示不限制
+	// Range of the file size (inclusive), 0 means no limit
+	MinSize int64
+	MaxSize int64
+
+	// Initiator 分段上传的发起者名称或ID，与发起者的名称和ID都进行比较
+	// Name or ID of the multipart upload initiator, compared with both the initiator's name and ID
+	Initiator string
+
+	// Action 规则动作：RuleActionKeep, RuleActionAbort
+	// Rule action: RuleActionKeep, RuleActionAbort
+	Action string
+
+	// OlderThan abort 动作只处理早于此时长的文件
+	// The abort action only handles files older than this duration
+	OlderThan time.Duration
+}
+
+// compiledRule 编译了正则表达式的规则
+// compiledRule is a rule with its regular expression compiled
+type compiledRule struct {
+	Rule
+	regex *regexp.Regexp
+}
+
+// compileRules 检查并编译规则
+// compileRules validates and compiles the rules
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i+1)
+		}
+		switch rule.Action {
+		case RuleActionKeep, RuleActionAbort:
+		default:
+			return nil, fmt.Errorf("规则 %s 的动作 '%s' 无效，有效选项为: keep, abort\nInvalid action '%s' of rule %s, valid options are: keep, abort", rule.Name, rule.Action, rule.Action, rule.Name)
+		}
+		if rule.OlderThan < 0 {
+			return nil, fmt.Errorf("规则 %s 的时间 %s 无效，不能为负数\nInvalid duration %s of rule %s, must not be negative", rule.Name, rule.OlderThan, rule.OlderThan, rule.Name)
+		}
+		if rule.MaxSize > 0 && rule.MinSize > rule.MaxSize {
+			return nil, fmt.Errorf("规则 %s 的最小大小大于最大大小\nThe minimum size of rule %s is larger than its maximum size", rule.Name, rule.Name)
+		}
+		if _, err := path.Match(rule.Bucket, ""); err != nil {
+			return nil, fmt.Errorf("规则 %s 的桶模式 '%s' 无效: %v\nInvalid bucket pattern '%s' of rule %s: %v", rule.Name, rule.Bucket, err, rule.Bucket, rule.Name, err)
+		}
+
+		c := compiledRule{Rule: rule}
+		if rule.Regex != "" {
+			re, err := regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("规则 %s 的正则表达式无效: %v\nInvalid regular expression of rule %s: %v", rule.Name, err, rule.Name, err)
+			}
+			c.regex = re
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// match 判断文件是否满足规则的所有条件
+// match reports whether the file meets all conditions of the rule
+func (rule *compiledRule) match(file FileInfo) bool {
+	if rule.Bucket != "" {
+		if ok, _ := path.Match(rule.Bucket, file.Bucket); !ok {
+			return false
+		}
+	}
+	if !strings.HasPrefix(file.Key, rule.Prefix) {
+		return false
+	}
+	if rule.regex != nil && !rule.regex.MatchString(file.Key) {
+		return false
+	}
+	if file.Size < rule.MinSize || (rule.MaxSize > 0 && file.Size > rule.MaxSize) {
+		return false
+	}
+	if rule.Initiator != "" && !matchIdentity([]string{rule.Initiator}, file.Initiator, file.InitiatorID) {
+		return false
+	}
+	return true
+}
+
+// stale 按第一条匹配的规则判断文件是否过期，并在文件上记录规则名称；没有匹配的规则时使用本次运行的截止时间。
+// age 为判断年龄的时间
+// stale judges whether the file is stale by the first matching rule and records the rule name on the file;
+// the cutoff of the run is used when no rule matches. age is the time the age is judged by
+func (c *S3Cleaner) stale(r *run, file *FileInfo, age time.Time) bool {
+	for i := range c.rules {
+		rule := &c.rules[i]
+		if !rule.match(*file) {
+			continue
+		}
+		file.Rule = rule.Name
+		if rule.Action == RuleActionKeep {
+			return false
+		}
+		return age.Before(r.start.Add(-rule.OlderThan))
+	}
+	return age.Before(r.cutoff)
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleMatch(t *testing.T) {
+	file := FileInfo{Bucket: "logs-1", Key: "tmp/part.bin", Size: 100, Initiator: "alice", InitiatorID: "arn:aws:iam::123456789012:user/alice"}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"empty rule matches everything", Rule{}, true},
+		{"bucket glob", Rule{Bucket: "logs-*"}, true},
+		{"other bucket", Rule{Bucket: "data-*"}, false},
+		{"prefix", Rule{Prefix: "tmp/"}, true},
+		{"other prefix", Rule{Prefix: "data/"}, false},
+		{"regex", Rule{Regex: `\.bin$`}, true},
+		{"other regex", Rule{Regex: `\.log$`}, false},
+		{"size within range", Rule{MinSize: 100, MaxSize: 100}, true},
+		{"smaller than minimum", Rule{MinSize: 101}, false},
+		{"larger than maximum", Rule{MaxSize: 99}, false},
+		{"initiator by display name", Rule{Initiator: "alice"}, true},
+		{"initiator by ID", Rule{Initiator: "arn:aws:iam::123456789012:user/alice"}, true},
+		{"other initiator", Rule{Initiator: "bob"}, false},
+		{"all conditions hold", Rule{Bucket: "logs-?", Prefix: "tmp/", Regex: "part", MinSize: 1, Initiator: "alice"}, true},
+		{"one condition fails", Rule{Bucket: "logs-?", Prefix: "tmp/", Initiator: "bob"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Action = RuleActionKeep
+			rules, err := compileRules([]Rule{tt.rule})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rules[0].match(file); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		want string
+	}{
+		{"invalid action", Rule{Action: "delete"}, "Invalid action 'delete' of rule #1"},
+		{"negative duration", Rule{Name: "tmp", Action: RuleActionAbort, OlderThan: -time.Hour}, "Invalid duration -1h0m0s of rule tmp"},
+		{"minimum above maximum", Rule{Action: RuleActionKeep, MinSize: 10, MaxSize: 5}, "minimum size of rule #1"},
+		{"invalid bucket pattern", Rule{Action: RuleActionKeep, Bucket: "logs-["}, "Invalid bucket pattern 'logs-['"},
+		{"invalid regex", Rule{Action: RuleActionKeep, Regex: "("}, "Invalid regular expression of rule #1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRules([]Rule{tt.rule})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("compileRules() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStaleFirstMatchingRule(t *testing.T) {
+	rules, err := compileRules([]Rule{
+		{Name: "keep-backups", Prefix: "backup/", Action: RuleActionKeep},
+		{Prefix: "tmp/", Action: RuleActionAbort, OlderThan: 24 * time.Hour},
+		{Name: "never-reached", Prefix: "tmp/", Action: RuleActionKeep},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &S3Cleaner{rules: rules}
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := &run{start: start, cutoff: start.Add(-7 * 24 * time.Hour)}
+	day := func(n int) time.Time { return start.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	tests := []struct {
+		key      string
+		age      time.Time
+		want     bool
+		wantRule string
+	}{
+		{"backup/db.bin", day(30), false, "keep-backups"},
+		{"tmp/part.bin", day(2), true, "#2"},
+		{"tmp/part.bin", start.Add(-time.Hour), false, "#2"},
+		{"data/file.bin", day(2), false, ""},
+		{"data/file.bin", day(8), true, ""},
+	}
+	for _, tt := range tests {
+		file := FileInfo{Bucket: "a", Key: tt.key}
+		if got := c.stale(r, &file, tt.age); got != tt.want || file.Rule != tt.wantRule {
+			t.Errorf("stale(%s, %s) = %v with rule %q, want %v with rule %q", tt.key, tt.age, got, file.Rule, tt.want, tt.wantRule)
+		}
+	}
+}
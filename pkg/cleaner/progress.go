@@ -0,0 +1,123 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"time"
+)
+
+// 进度事件类型
+// Progress event types
+const (
+	ProgressEventProgress = "progress"
+	ProgressEventDone     = "done"
+)
+
+// ProgressEvent 扫描和删除进度
+// ProgressEvent is the scan and delete progress
+type ProgressEvent struct {
+	Event          string  `json:"event"`
+	Bucket         string  `json:"bucket"`
+	BucketsDone    int     `json:"buckets_done"`
+	BucketsTotal   int     `json:"buckets_total"`
+	Pages          int     `json:"pages"`
+	FilesScanned   int     `json:"files_scanned"`
+	FilesToDelete  int     `json:"files_to_delete"`
+	SizeToDelete   int64   `json:"size_to_delete"`
+	FilesDeleted   int     `json:"files_deleted"`
+	FilesFailed    int     `json:"files_failed"`
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+}
+
+// progress 记录一次运行的进度并在变化时调用回调，nil 表示不记录
+// progress tracks the progress of one run and calls the hook on every change, nil means disabled
+type progress struct {
+	hook  func(ProgressEvent)
+	start time.Time
+	state ProgressEvent
+}
+
+// newProgress 创建进度记录，hook 为 nil 时返回 nil
+// newProgress creates a progress tracker, returns nil if hook is nil
+func newProgress(hook func(ProgressEvent), bucketsTotal int) *progress {
+	if hook == nil {
+		return nil
+	}
+	return &progress{
+		hook:  hook,
+		start: time.Now(),
+		state: ProgressEvent{Event: ProgressEventProgress, BucketsTotal: bucketsTotal},
+	}
+}
+
+// update 修改进度并通知回调
+// update modifies the progress and notifies the hook
+func (p *progress) update(fn func(state *ProgressEvent)) {
+	if p == nil {
+		return
+	}
+	fn(&p.state)
+	p.state.ElapsedSeconds = time.Since(p.start).Seconds()
+	p.hook(p.state)
+}
+
+// startBucket 记录开始处理一个桶
+// startBucket records that a bucket is being processed
+func (p *progress) startBucket(bucket string) {
+	p.update(func(state *ProgressEvent) {
+		state.Bucket = bucket
+	})
+}
+
+// finishBucket 记录一个桶处理完成，包括失败和从检查点跳过的桶
+// finishBucket records that a bucket is finished, including failed buckets and buckets skipped by the checkpoint
+func (p *progress) finishBucket() {
+	p.update(func(state *ProgressEvent) {
+		state.BucketsDone++
+	})
+}
+
+// addPage 记录扫描完成的一页文件
+// addPage records one scanned page of files
+func (p *progress) addPage(files []FileInfo) {
+	p.update(func(state *ProgressEvent) {
+		state.Pages++
+		state.FilesScanned += len(files)
+		for _, file := range files {
+			if file.ShouldDelete {
+				state.FilesToDelete++
+				state.SizeToDelete += file.Size
+			}
+		}
+	})
+}
+
+// addDeleted 记录删除尝试的结果
+// addDeleted records the results of delete attempts
+func (p *progress) addDeleted(files []*FileInfo) {
+	p.update(func(state *ProgressEvent) {
+		for _, file := range files {
+			if file.DeleteSuccess == nil {
+				continue
+			}
+			if *file.DeleteSuccess {
+				state.FilesDeleted++
+			} else {
+				state.FilesFailed++
+			}
+		}
+	})
+}
+
+// finish 发送最终的完成事件
+// finish sends the final done event
+func (p *progress) finish() {
+	p.update(func(state *ProgressEvent) {
+		state.Event = ProgressEventDone
+	})
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// compareFunc 比较两个文件的排序先后
+// compareFunc compares the order of two files
+type compareFunc func(a, b FileInfo) int
+
+// parseSortBy 解析排序方式：size（从大到小）, age（从旧到新）, key, bucket
+// parseSortBy parses the sort order: size (largest first), age (oldest first), key, bucket
+func parseSortBy(sortBy string, reverse bool) (compareFunc, error) {
+	var compare compareFunc
+	switch sortBy {
+	case "":
+		if reverse {
+			return nil, fmt.Errorf("--reverse 需要同时指定 --sortBy\n--reverse requires --sortBy")
+		}
+		return nil, nil
+	case "size":
+		compare = func(a, b FileInfo) int {
+			return cmp.Or(cmp.Compare(b.Size, a.Size), compareLocation(a, b))
+		}
+	case "age":
+		compare = func(a, b FileInfo) int {
+			return cmp.Or(a.AgeTime().Compare(b.AgeTime()), compareLocation(a, b))
+		}
+	case "key":
+		compare = func(a, b FileInfo) int {
+			return cmp.Or(strings.Compare(a.Key, b.Key), strings.Compare(a.Bucket, b.Bucket))
+		}
+	case "bucket":
+		compare = compareLocation
+	default:
+		return nil, fmt.Errorf("无效的排序方式 '%s'，有效选项为: size, age, key, bucket\nInvalid sort order '%s', valid options are: size, age, key, bucket", sortBy, sortBy)
+	}
+
+	if reverse {
+		return func(a, b FileInfo) int {
+			return compare(b, a)
+		}, nil
+	}
+	return compare, nil
+}
+
+// compareLocation 按桶和键比较两个文件
+// compareLocation compares two files by bucket and key
+func compareLocation(a, b FileInfo) int {
+	return cmp.Or(strings.Compare(a.Bucket, b.Bucket), strings.Compare(a.Key, b.Key))
+}
+
+// deleteBudget 一次运行中允许删除的数量和容量上限
+// deleteBudget limits the number and size of deletions in one run
+type deleteBudget struct {
+	maxCount int
+	maxBytes int64
+	count    int
+	bytes    int64
+}
+
+// newDeleteBudget 根据选项创建删除预算，0 表示不限制
+// newDeleteBudget creates a delete budget from options, 0 means unlimited
+func newDeleteBudget(opts Options) *deleteBudget {
+	return &deleteBudget{
+		maxCount: opts.MaxDeleteCount,
+		maxBytes: opts.MaxDeleteBytes,
+	}
+}
+
+// allow 检查删除指定大小的文件是否仍在预算内
+// allow checks whether deleting a file of the given size still fits in the budget
+func (b *deleteBudget) allow(size int64) bool {
+	if b.maxCount > 0 && b.count+1 > b.maxCount {
+		return false
+	}
+	if b.maxBytes > 0 && b.bytes+size > b.maxBytes {
+		return false
+	}
+	return true
+}
+
+// consume 记录一次删除尝试
+// consume records one deletion attempt
+func (b *deleteBudget) consume(size int64) {
+	b.count++
+	b.bytes += size
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSortByBeforeDeleteBudget(t *testing.T) {
+	now := time.Now()
+	day := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+
+	tests := []struct {
+		name           string
+		sortBy         string
+		reverse        bool
+		maxDeleteCount int
+		maxDeleteBytes int64
+		wantAborted    []string
+	}{
+		{name: "largest first", sortBy: "size", maxDeleteCount: 2, wantAborted: []string{"big", "medium"}},
+		{name: "oldest first", sortBy: "age", maxDeleteCount: 2, wantAborted: []string{"oldest", "old"}},
+		{name: "smallest first", sortBy: "size", reverse: true, maxDeleteCount: 2, wantAborted: []string{"small", "old"}},
+		{name: "size budget skips what does not fit", sortBy: "size", maxDeleteBytes: 5 << 10, wantAborted: []string{"big", "old", "small"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 上传 ID 即键名，大小为分段数 × 1KiB：big 4KiB, medium 3KiB, oldest 2KiB, old 1KiB, small 0
+			// Upload IDs equal the keys, sizes are the number of parts × 1KiB: big 4KiB, medium 3KiB, oldest 2KiB, old 1KiB, small 0
+			client := newFakeS3()
+			client.addUpload("a", "medium", "medium", day(10), day(10), day(10), day(10))
+			client.addUpload("a", "oldest", "oldest", day(40), day(40), day(40))
+			client.addUpload("b", "big", "big", day(20), day(20), day(20), day(20), day(20))
+			client.addUpload("b", "small", "small", day(15))
+			client.addUpload("b", "old", "old", day(30), day(30))
+			client.addUpload("b", "fresh", "fresh", now, now, now, now, now, now)
+
+			opts := DefaultOptions()
+			opts.Client = client
+			opts.SortBy = tt.sortBy
+			opts.Reverse = tt.reverse
+			opts.MaxDeleteCount = tt.maxDeleteCount
+			opts.MaxDeleteBytes = tt.maxDeleteBytes
+			c, err := New(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := c.Clean(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(client.aborted, tt.wantAborted) {
+				t.Errorf("aborted = %v, want %v", client.aborted, tt.wantAborted)
+			}
+			for _, file := range result.Files {
+				aborted := slices.Contains(tt.wantAborted, file.Key)
+				if file.ShouldDelete && file.Skipped == aborted {
+					t.Errorf("%s: Skipped = %v, want %v", file.Key, file.Skipped, !aborted)
+				}
+			}
+		})
+	}
+}
+
+func TestParseSortBy(t *testing.T) {
+	files := []FileInfo{
+		{Bucket: "b", Key: "x", Size: 100, ModTime: time.Unix(300, 0)},
+		{Bucket: "a", Key: "y", Size: 300, ModTime: time.Unix(100, 0)},
+		{Bucket: "a", Key: "x", Size: 200, ModTime: time.Unix(200, 0)},
+	}
+	keys := func(files []FileInfo) []string {
+		var keys []string
+		for _, f := range files {
+			keys = append(keys, f.Bucket+"/"+f.Key)
+		}
+		return keys
+	}
+
+	tests := []struct {
+		sortBy  string
+		reverse bool
+		want    []string
+	}{
+		{"size", false, []string{"a/y", "a/x", "b/x"}},
+		{"age", false, []string{"a/y", "a/x", "b/x"}},
+		{"age", true, []string{"b/x", "a/x", "a/y"}},
+		{"key", false, []string{"a/x", "b/x", "a/y"}},
+		{"bucket", false, []string{"a/x", "a/y", "b/x"}},
+	}
+	for _, tt := range tests {
+		compare, err := parseSortBy(tt.sortBy, tt.reverse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorted := slices.Clone(files)
+		slices.SortStableFunc(sorted, compare)
+		if got := keys(sorted); !slices.Equal(got, tt.want) {
+			t.Errorf("sortBy %s reverse %v = %v, want %v", tt.sortBy, tt.reverse, got, tt.want)
+		}
+	}
+
+	if _, err := parseSortBy("", true); err == nil {
+		t.Error("parseSortBy(\"\", reverse) succeeded, want --reverse to require --sortBy")
+	}
+	if _, err := parseSortBy("name", false); err == nil {
+		t.Error("parseSortBy(name) succeeded, want an invalid sort order error")
+	}
+}
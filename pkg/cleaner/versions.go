@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// versionEntry 对象版本或删除标记
+// versionEntry is an object version or a delete marker
+type versionEntry struct {
+	Key          string
+	VersionID    string
+	Size         int64
+	StorageClass string
+	LastModified time.Time
+	IsLatest     bool
+	DeleteMarker bool
+}
+
+// versionScanner 按键顺序遍历版本，跨页保留同一个键的状态
+// versionScanner walks versions in key order, keeping the state of the current key across pages
+type versionScanner struct {
+	cleaner      *S3Cleaner
+	bucket       string
+	run          *run
+	keepVersions int
+
+	key           string
+	finishedKey   string
+	successorTime time.Time
+	noncurrent    int
+	latestMarker  *versionEntry
+
+	// otherEntries 当前键除最新删除标记外的版本和删除标记数，为 0 时最新删除标记是孤立的
+	// otherEntries counts the versions and delete markers of the current key besides its latest delete marker,
+	// the latest delete marker is orphaned when it is 0
+	otherEntries int
+}
+
+// add 处理一个版本，返回需要报告的文件
+// add processes one version and returns the files to report
+func (s *versionScanner) add(entry versionEntry) []FileInfo {
+	var files []FileInfo
+	if entry.Key != s.key {
+		files = s.finishKey()
+		if s.key != "" {
+			s.finishedKey = s.key
+		}
+		s.key = entry.Key
+	}
+
+	if entry.IsLatest {
+		// 最新版本不是清理对象；最新的删除标记在确认没有其他版本后才是孤立的
+		// The latest version is not a cleaning target; the latest delete marker is orphaned only if there are no other versions
+		if entry.DeleteMarker {
+			s.latestMarker = &entry
+		} else {
+			s.otherEntries++
+		}
+		s.successorTime = entry.LastModified
+		return files
+	}
+
+	// 非当前版本从被新版本取代时开始计算年龄，保留最新的N个；非当前的删除标记不包含数据，不计入保留的版本数
+	// Noncurrent versions age from when they were superseded, and the newest N are kept;
+	// noncurrent delete markers hold no data and do not count toward the kept versions
+	s.otherEntries++
+	fileType := FileTypeVersion
+	if entry.DeleteMarker {
+		fileType = FileTypeDeleteMarker
+	} else {
+		s.noncurrent++
+	}
+	file := FileInfo{
+		Bucket:       s.bucket,
+		Key:          entry.Key,
+		Size:         entry.Size,
+		ModTime:      entry.LastModified,
+		Type:         fileType,
+		StorageClass: entry.StorageClass,
+		VersionID:    entry.VersionID,
+	}
+	file.ShouldDelete = s.cleaner.stale(s.run, &file, s.successorTime) && (entry.DeleteMarker || s.noncurrent > s.keepVersions)
+	if s.cleaner.keepLogged(s.run, file) {
+		files = append(files, file)
+	}
+	s.successorTime = entry.LastModified
+	return files
+}
+
+// finishKey 结束当前键，返回孤立的删除标记
+// finishKey finishes the current key and returns its orphaned delete marker
+func (s *versionScanner) finishKey() []FileInfo {
+	var files []FileInfo
+	if s.latestMarker != nil && s.otherEntries == 0 {
+		file := FileInfo{
+			Bucket:    s.bucket,
+			Key:       s.latestMarker.Key,
+			ModTime:   s.latestMarker.LastModified,
+			Type:      FileTypeDeleteMarker,
+			VersionID: s.latestMarker.VersionID,
+		}
+		file.ShouldDelete = s.cleaner.stale(s.run, &file, file.ModTime)
+		if s.cleaner.keepLogged(s.run, file) {
+			files = append(files, file)
+		}
+	}
+
+	s.successorTime = time.Time{}
+	s.noncurrent = 0
+	s.otherEntries = 0
+	s.latestMarker = nil
+	return files
+}
+
+// processVersionsInBucket 处理一个桶中的非当前版本和孤立的删除标记，并按运行状态逐页删除
+// processVersionsInBucket processes noncurrent versions and orphaned delete markers in one bucket
+// and deletes them page by page as the run requires
+func (c *S3Cleaner) processVersionsInBucket(ctx context.Context, r *run, bucket string) ([]FileInfo, error) {
+	// 从检查点记录的键之后继续
+	// Continue after the key recorded in the checkpoint
+	resumeKey := r.cp.markers(bucket).KeyMarker
+	keyMarker := optionalString(resumeKey)
+	var versionIdMarker *string
+	files := []FileInfo{}
+	page := 0
+	scanner := &versionScanner{
+		cleaner:      c,
+		bucket:       bucket,
+		run:          r,
+		keepVersions: c.opts.KeepVersions,
+		finishedKey:  resumeKey,
+	}
+
+	// 分页列出所有版本
+	// List all versions with pagination
+	for {
+		resp, err := c.client.ListObjectVersions(ctx, &s3.ListObjectVersionsInput{
+			Bucket:          aws.String(bucket),
+			KeyMarker:       keyMarker,
+			VersionIdMarker: versionIdMarker,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("无法列出桶 %s 中的对象版本: %v\nFailed to list object versions in bucket %s: %v", bucket, err, bucket, err)
+		}
+
+		// 合并版本和删除标记，按键升序、同一个键内从新到旧排列
+		// Merge versions and delete markers, ordered by key and from newest to oldest within a key
+		entries := make([]versionEntry, 0, len(resp.Versions)+len(resp.DeleteMarkers))
+		for _, version := range resp.Versions {
+			entries = append(entries, versionEntry{
+				Key:          aws.ToString(version.Key),
+				VersionID:    aws.ToString(version.VersionId),
+				Size:         aws.ToInt64(version.Size),
+				StorageClass: string(version.StorageClass),
+				LastModified: aws.ToTime(version.LastModified),
+				IsLatest:     aws.ToBool(version.IsLatest),
+			})
+		}
+		for _, marker := range resp.DeleteMarkers {
+			entries = append(entries, versionEntry{
+				Key:          aws.ToString(marker.Key),
+				VersionID:    aws.ToString(marker.VersionId),
+				LastModified: aws.ToTime(marker.LastModified),
+				IsLatest:     aws.ToBool(marker.IsLatest),
+				DeleteMarker: true,
+			})
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Key != entries[j].Key {
+				return entries[i].Key < entries[j].Key
+			}
+			if entries[i].IsLatest != entries[j].IsLatest {
+				return entries[i].IsLatest
+			}
+			return entries[i].LastModified.After(entries[j].LastModified)
+		})
+
+		page++
+		c.log.Debug("已列出一页对象版本 | Listed a page of object versions", "bucket", bucket, "page", page,
+			"versions", len(resp.Versions), "delete_markers", len(resp.DeleteMarkers))
+		pageStart := len(files)
+		for _, entry := range entries {
+			files = append(files, scanner.add(entry)...)
+		}
+
+		// 如果没有更多页，则结束最后一个键并退出循环
+		// If no more pages, finish the last key and exit loop
+		truncated := resp.IsTruncated != nil && *resp.IsTruncated
+		if !truncated {
+			files = append(files, scanner.finishKey()...)
+		}
+
+		// 删除当前页中需要删除的版本
+		// Delete versions in current page that should be deleted
+		r.progress.addPage(files[pageStart:])
+		c.deleteFiles(ctx, r, files[pageStart:])
+
+		if !truncated {
+			break
+		}
+		keyMarker = resp.NextKeyMarker
+		versionIdMarker = resp.NextVersionIdMarker
+
+		// 保留版本数和被取代时间依赖同一个键的所有版本，因此只记录已经处理完的最后一个键，
+		// 恢复时从下一个键的第一个版本重新开始
+		// Kept versions and superseded times depend on all versions of a key, so only the last finished key is recorded,
+		// and a resumed run restarts from the first version of the next key
+		if err := r.cp.savePage(bucket, checkpointMarkers{KeyMarker: scanner.finishedKey}); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
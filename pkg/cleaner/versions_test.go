@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestVersionScanner(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := func(n int) time.Time { return start.Add(-time.Duration(n) * 24 * time.Hour) }
+	version := func(key, id string, modified time.Time, latest bool) versionEntry {
+		return versionEntry{Key: key, VersionID: id, Size: 100, LastModified: modified, IsLatest: latest}
+	}
+	marker := func(key, id string, modified time.Time, latest bool) versionEntry {
+		return versionEntry{Key: key, VersionID: id, LastModified: modified, IsLatest: latest, DeleteMarker: true}
+	}
+
+	tests := []struct {
+		name         string
+		keepVersions int
+		entries      []versionEntry
+		want         map[string]bool
+	}{
+		{
+			name: "noncurrent versions age from when they were superseded",
+			entries: []versionEntry{
+				version("a", "a3", day(1), true),
+				version("a", "a2", day(10), false),
+				version("a", "a1", day(20), false),
+			},
+			want: map[string]bool{"a2": false, "a1": true},
+		},
+		{
+			name:         "newest noncurrent versions are kept",
+			keepVersions: 1,
+			entries: []versionEntry{
+				version("a", "a4", day(10), true),
+				version("a", "a3", day(20), false),
+				version("a", "a2", day(30), false),
+				version("a", "a1", day(40), false),
+			},
+			want: map[string]bool{"a3": false, "a2": true, "a1": true},
+		},
+		{
+			name:         "noncurrent delete markers do not count toward kept versions",
+			keepVersions: 1,
+			entries: []versionEntry{
+				version("a", "a4", day(10), true),
+				marker("a", "m2", day(20), false),
+				marker("a", "m1", day(30), false),
+				version("a", "a2", day(40), false),
+				version("a", "a1", day(50), false),
+			},
+			want: map[string]bool{"m2": true, "m1": true, "a2": false, "a1": true},
+		},
+		{
+			name: "latest delete marker without versions is orphaned",
+			entries: []versionEntry{
+				marker("a", "m1", day(10), true),
+				version("b", "b2", day(1), true),
+				version("b", "b1", day(10), false),
+			},
+			want: map[string]bool{"m1": true, "b1": false},
+		},
+		{
+			name: "latest delete marker with versions is kept",
+			entries: []versionEntry{
+				marker("a", "m1", day(10), true),
+				version("a", "a1", day(20), false),
+			},
+			want: map[string]bool{"a1": true},
+		},
+		{
+			name: "latest delete marker with older delete markers is kept",
+			entries: []versionEntry{
+				marker("a", "m2", day(10), true),
+				marker("a", "m1", day(20), false),
+			},
+			want: map[string]bool{"m1": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &S3Cleaner{log: slog.New(slog.DiscardHandler)}
+			s := &versionScanner{
+				cleaner:      c,
+				bucket:       "bkt",
+				run:          &run{start: start, cutoff: start.Add(-7 * 24 * time.Hour)},
+				keepVersions: tt.keepVersions,
+			}
+			var files []FileInfo
+			for _, entry := range tt.entries {
+				files = append(files, s.add(entry)...)
+			}
+			files = append(files, s.finishKey()...)
+
+			got := map[string]bool{}
+			for _, file := range files {
+				got[file.VersionID] = file.ShouldDelete
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("files = %v, want %v", got, tt.want)
+			}
+			for id, want := range tt.want {
+				if shouldDelete, ok := got[id]; !ok || shouldDelete != want {
+					t.Errorf("version %s: ShouldDelete = %v (reported %v), want %v", id, shouldDelete, ok, want)
+				}
+			}
+		})
+	}
+}
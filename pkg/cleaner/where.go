@@ -0,0 +1,347 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/units"
+)
+
+// whereFunc 编译后的过滤表达式，now 为计算年龄的当前时间
+// whereFunc is a compiled filter expression, now is the current time used to compute ages
+type whereFunc func(file FileInfo, now time.Time) bool
+
+// 过滤表达式中字段的类型
+// Kinds of fields in filter expressions
+const (
+	whereString = iota
+	whereSize
+	whereAge
+)
+
+// whereField 过滤表达式中可以使用的字段
+// whereField is a field that can be used in filter expressions
+type whereField struct {
+	kind int
+	text func(file FileInfo) string
+	num  func(file FileInfo, now time.Time) int64
+}
+
+// whereFields 过滤表达式中可以使用的所有字段
+// whereFields are all fields that can be used in filter expressions
+var whereFields = map[string]whereField{
+	"bucket":       {kind: whereString, text: func(f FileInfo) string { return f.Bucket }},
+	"key":          {kind: whereString, text: func(f FileInfo) string { return f.Key }},
+	"type":         {kind: whereString, text: func(f FileInfo) string { return f.Type }},
+	"initiator":    {kind: whereString, text: func(f FileInfo) string { return f.Initiator }},
+	"owner":        {kind: whereString, text: func(f FileInfo) string { return f.Owner }},
+	"storageClass": {kind: whereString, text: func(f FileInfo) string { return f.StorageClass }},
+	"uploadId":     {kind: whereString, text: func(f FileInfo) string { return f.UploadID }},
+	"versionId":    {kind: whereString, text: func(f FileInfo) string { return f.VersionID }},
+	"rule":         {kind: whereString, text: func(f FileInfo) string { return f.Rule }},
+	"size":         {kind: whereSize, num: func(f FileInfo, _ time.Time) int64 { return f.Size }},
+	"age":          {kind: whereAge, num: func(f FileInfo, now time.Time) int64 { return int64(now.Sub(f.AgeTime())) }},
+}
+
+// whereFieldNames 返回所有字段名，按字母排序
+// whereFieldNames returns all field names in alphabetical order
+func whereFieldNames() string {
+	names := make([]string, 0, len(whereFields))
+	for name := range whereFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// whereToken 过滤表达式的词法单元
+// whereToken is a lexical token of a filter expression
+type whereToken struct {
+	kind string // ident, value, string, op, eof
+	text string
+	pos  int
+}
+
+// whereError 过滤表达式的解析错误，位置从 1 开始
+// whereError is a parse error of a filter expression, positions start at 1
+type whereError struct {
+	expr string
+	pos  int
+	zh   string
+	en   string
+}
+
+func (e *whereError) Error() string {
+	return fmt.Sprintf("无效的 --where 表达式（位置 %d）: %s\nInvalid --where expression (position %d): %s\n  %s\n  %s^",
+		e.pos, e.zh, e.pos, e.en, e.expr, strings.Repeat(" ", e.pos-1))
+}
+
+// lexWhere 将过滤表达式拆分为词法单元
+// lexWhere splits a filter expression into tokens
+func lexWhere(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+	i := 0
+	for i < len(expr) {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, whereToken{kind: "op", text: string(c), pos: i + 1})
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="),
+			strings.HasPrefix(expr[i:], "<="), strings.HasPrefix(expr[i:], ">="):
+			tokens = append(tokens, whereToken{kind: "op", text: expr[i : i+2], pos: i + 1})
+			i += 2
+		case c == '<' || c == '>' || c == '!':
+			tokens = append(tokens, whereToken{kind: "op", text: string(c), pos: i + 1})
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, &whereError{expr: expr, pos: i + 1, zh: "字符串没有结束的引号", en: "unterminated string"}
+			}
+			text, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, &whereError{expr: expr, pos: i + 1, zh: "无效的字符串", en: "invalid string"}
+			}
+			tokens = append(tokens, whereToken{kind: "string", text: text, pos: i + 1})
+			i = end + 1
+		case isWhereWordByte(c):
+			end := i
+			for end < len(expr) && isWhereWordByte(expr[end]) {
+				end++
+			}
+			kind := "ident"
+			if c >= '0' && c <= '9' {
+				kind = "value"
+			}
+			tokens = append(tokens, whereToken{kind: kind, text: expr[i:end], pos: i + 1})
+			i = end
+		default:
+			return nil, &whereError{expr: expr, pos: i + 1, zh: fmt.Sprintf("无法识别的字符 '%c'", c), en: fmt.Sprintf("unexpected character '%c'", c)}
+		}
+	}
+	return append(tokens, whereToken{kind: "eof", pos: len(expr) + 1}), nil
+}
+
+// isWhereWordByte 判断字符是否可以出现在字段名或数值中
+// isWhereWordByte reports whether the character can appear in a field name or a value
+func isWhereWordByte(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// whereParser 过滤表达式的递归下降解析器
+// whereParser is a recursive descent parser of filter expressions
+type whereParser struct {
+	expr   string
+	tokens []whereToken
+	pos    int
+}
+
+// compileWhere 编译过滤表达式，表达式为空时返回 nil
+// compileWhere compiles a filter expression, returning nil when the expression is empty
+//
+// 语法 | Grammar:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = field ( "==" | "!=" | "<" | "<=" | ">" | ">=" | "matches" ) value
+func compileWhere(expr string) (whereFunc, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	tokens, err := lexWhere(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &whereParser{expr: expr, tokens: tokens}
+	fn, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != "eof" {
+		return nil, p.errorf(tok, "多余的 '%s'", "unexpected '%s'", tok.text)
+	}
+	return fn, nil
+}
+
+func (p *whereParser) peek() whereToken {
+	return p.tokens[p.pos]
+}
+
+func (p *whereParser) next() whereToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != "eof" {
+		p.pos++
+	}
+	return tok
+}
+
+// errorf 返回指向词法单元的解析错误
+// errorf returns a parse error pointing at the token
+func (p *whereParser) errorf(tok whereToken, zh, en string, args ...any) error {
+	return &whereError{expr: p.expr, pos: tok.pos, zh: fmt.Sprintf(zh, args...), en: fmt.Sprintf(en, args...)}
+}
+
+func (p *whereParser) parseOr() (whereFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "||" && p.peek().kind == "op" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f FileInfo, now time.Time) bool { return l(f, now) || right(f, now) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseAnd() (whereFunc, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().text == "&&" && p.peek().kind == "op" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(f FileInfo, now time.Time) bool { return l(f, now) && right(f, now) }
+	}
+	return left, nil
+}
+
+func (p *whereParser) parseUnary() (whereFunc, error) {
+	tok := p.peek()
+	if tok.kind == "op" && tok.text == "!" {
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(f FileInfo, now time.Time) bool { return !inner(f, now) }, nil
+	}
+	if tok.kind == "op" && tok.text == "(" {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.text != ")" || closing.kind != "op" {
+			return nil, p.errorf(closing, "缺少 ')'", "missing ')'")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *whereParser) parseComparison() (whereFunc, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != "ident" {
+		return nil, p.errorf(fieldTok, "应为字段名，有效字段为: %s", "expected a field name, valid fields are: %s", whereFieldNames())
+	}
+	field, ok := whereFields[fieldTok.text]
+	if !ok {
+		return nil, p.errorf(fieldTok, "未知的字段 '%s'，有效字段为: %s", "unknown field '%s', valid fields are: %s", fieldTok.text, whereFieldNames())
+	}
+
+	opTok := p.next()
+	op := opTok.text
+	switch {
+	case opTok.kind == "ident" && op == "matches":
+	case opTok.kind == "op" && (op == "==" || op == "!=" || op == "<" || op == "<=" || op == ">" || op == ">="):
+	default:
+		return nil, p.errorf(opTok, "应为比较运算符: ==, !=, <, <=, >, >=, matches", "expected a comparison operator: ==, !=, <, <=, >, >=, matches")
+	}
+
+	valueTok := p.next()
+	if field.kind == whereString {
+		return p.compileString(field, op, opTok, valueTok)
+	}
+	return p.compileNumber(field, fieldTok.text, op, opTok, valueTok)
+}
+
+// compileString 编译字符串字段的比较
+// compileString compiles a comparison of a string field
+func (p *whereParser) compileString(field whereField, op string, opTok, valueTok whereToken) (whereFunc, error) {
+	if valueTok.kind != "string" {
+		return nil, p.errorf(valueTok, "应为带引号的字符串", "expected a quoted string")
+	}
+	value := valueTok.text
+	switch op {
+	case "==":
+		return func(f FileInfo, _ time.Time) bool { return field.text(f) == value }, nil
+	case "!=":
+		return func(f FileInfo, _ time.Time) bool { return field.text(f) != value }, nil
+	case "matches":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.errorf(valueTok, "无效的正则表达式: %v", "invalid regular expression: %v", err)
+		}
+		return func(f FileInfo, _ time.Time) bool { return re.MatchString(field.text(f)) }, nil
+	default:
+		return nil, p.errorf(opTok, "字符串字段只支持 ==, != 和 matches", "string fields only support ==, != and matches")
+	}
+}
+
+// compileNumber 编译 size 和 age 字段的比较，值分别为容量（如 1GiB）和时间（如 3d）
+// compileNumber compiles a comparison of the size and age fields, whose values are sizes (e.g. 1GiB) and durations (e.g. 3d)
+func (p *whereParser) compileNumber(field whereField, name, op string, opTok, valueTok whereToken) (whereFunc, error) {
+	if op == "matches" {
+		return nil, p.errorf(opTok, "%s 不支持 matches", "%s does not support matches", name)
+	}
+	if valueTok.kind != "value" {
+		return nil, p.errorf(valueTok, "%s 应与数值比较", "%s must be compared with a number", name)
+	}
+
+	var value int64
+	if field.kind == whereSize {
+		size, err := units.ParseSize(valueTok.text)
+		if err != nil {
+			return nil, p.errorf(valueTok, "无效的容量 '%s'，如 '500MB' 或 '10GiB'", "invalid size '%s', e.g. '500MB' or '10GiB'", valueTok.text)
+		}
+		value = size
+	} else {
+		age, err := units.ParseDuration(valueTok.text)
+		if err != nil {
+			return nil, p.errorf(valueTok, "无效的时间 '%s'，如 '7d' 或 '72h'", "invalid duration '%s', e.g. '7d' or '72h'", valueTok.text)
+		}
+		value = int64(age)
+	}
+
+	compare := map[string]func(a, b int64) bool{
+		"==": func(a, b int64) bool { return a == b },
+		"!=": func(a, b int64) bool { return a != b },
+		"<":  func(a, b int64) bool { return a < b },
+		"<=": func(a, b int64) bool { return a <= b },
+		">":  func(a, b int64) bool { return a > b },
+		">=": func(a, b int64) bool { return a >= b },
+	}[op]
+	return func(f FileInfo, now time.Time) bool { return compare(field.num(f, now), value) }, nil
+}
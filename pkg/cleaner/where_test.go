@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package cleaner
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompileWhere(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	file := FileInfo{
+		Bucket:       "logs-1",
+		Key:          "tmp/part.bin",
+		Size:         2 << 30,
+		ModTime:      now.Add(-4 * 24 * time.Hour),
+		Type:         FileTypeUpload,
+		Initiator:    "alice",
+		StorageClass: "STANDARD",
+	}
+	recent := now.Add(-time.Hour)
+
+	tests := []struct {
+		expr string
+		file FileInfo
+		want bool
+	}{
+		{`size > 1GiB`, file, true},
+		{`size <= 1GiB`, file, false},
+		{`size == 2147483648`, file, true},
+		{`age > 3d`, file, true},
+		{`age < 72h`, file, false},
+		{`age > 3d`, FileInfo{ModTime: now, LastPartTime: &recent}, false},
+		{`key matches "^tmp/"`, file, true},
+		{`key matches "\\.log$"`, file, false},
+		{`bucket == "logs-1" && initiator != "bob"`, file, true},
+		{`bucket == "logs-2" || type == "upload"`, file, true},
+		{`!(size > 1GiB) || storageClass == "GLACIER"`, file, false},
+		{`size > 1GiB && (key matches "^data/" || age > 3d)`, file, true},
+		{`size > 1GiB && key matches "^data/" || age > 3d`, file, true},
+		{`rule == ""`, file, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			where, err := compileWhere(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := where(tt.file, now); got != tt.want {
+				t.Errorf("where(file) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileWhereEmpty(t *testing.T) {
+	where, err := compileWhere("  ")
+	if err != nil || where != nil {
+		t.Errorf("compileWhere(blank) = %v, %v, want nil, nil", where != nil, err)
+	}
+}
+
+func TestCompileWhereErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		pos  int
+	}{
+		{`sizee > 1GiB`, 1},
+		{`size > 1XB`, 8},
+		{`age > 3w`, 7},
+		{`size matches "x"`, 6},
+		{`key > "a"`, 5},
+		{`key == tmp`, 8},
+		{`size > "1GiB"`, 8},
+		{`key matches "("`, 13},
+		{`(size > 1GiB`, 13},
+		{`size > 1GiB)`, 12},
+		{`key == "tmp`, 8},
+		{`size > 1GiB & age > 3d`, 13},
+		{`size >`, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := compileWhere(tt.expr)
+			var whereErr *whereError
+			if !errors.As(err, &whereErr) {
+				t.Fatalf("compileWhere() error = %v, want a whereError", err)
+			}
+			if whereErr.pos != tt.pos {
+				t.Errorf("error position = %d, want %d\n%v", whereErr.pos, tt.pos, err)
+			}
+		})
+	}
+}
+
+func TestKeepUsesRunStart(t *testing.T) {
+	where, err := compileWhere(`age < 3d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &S3Cleaner{where: where}
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	file := FileInfo{ModTime: start.Add(-2 * 24 * time.Hour)}
+	if !c.keep(&run{start: start}, file) {
+		t.Error("keep() = false, want the age computed from the run start")
+	}
+}
@@ -8,18 +8,31 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
-	"regexp"
-	"strconv"
+	"io"
+	"os"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/units"
 )
 
 // Config 存储命令行配置
 // Config stores command line configuration
 type Config struct {
-	// Bucket 存储桶名称，为空表示所有桶
-	// Bucket name, empty means all buckets
-	Bucket string
+	// Buckets 存储桶名称或 glob 模式（如 logs-*），可重复指定，为空表示所有桶
+	// Bucket names or glob patterns (e.g. logs-*), may be repeated, empty means all buckets
+	Buckets []string
+
+	// ExcludeBuckets 排除的存储桶名称或 glob 模式
+	// Bucket names or glob patterns to exclude
+	ExcludeBuckets []string
+
+	// BucketsFrom 从文件读取存储桶名称或 glob 模式，每行一个，- 表示标准输入
+	// Read bucket names or glob patterns from a file, one per line, - means standard input
+	BucketsFrom string
 
 	// Time 查找早于此时间的文件，如 '7d'（7天前）或 '72h'（72小时前）
 	// Find files older than this time, e.g. '7d' (7 days ago) or '72h' (72 hours ago)
@@ -33,39 +46,253 @@ type Config struct {
 	// Output format: table, json, csv
 	Format string
 
-	// ExpirationTime 解析后的过期时间
-	// Parsed expiration time
-	ExpirationTime time.Time
+	// TimeZone 输出时间使用的时区：local（本地时区）, UTC 或 IANA 时区名称，如 Asia/Shanghai
+	// Time zone of the output times: local (the local time zone), UTC or an IANA zone name such as Asia/Shanghai
+	TimeZone string
+
+	// SizeUnits 输出容量的单位：iec（KiB, MiB，以 1024 为进制）, si（kB, MB，以 1000 为进制）, bytes（字节）
+	// Units of the output sizes: iec (KiB, MiB, powers of 1024), si (kB, MB, powers of 1000), bytes
+	SizeUnits string
+
+	// AgeBasis uploads 模式下判断年龄的依据：initiated（发起时间）, lastPart（最新分段的上传时间）
+	// What the age of multipart uploads is judged by: initiated (initiation time), lastPart (upload time of the newest part)
+	AgeBasis string
+
+	// Target 清理目标：uploads（未完成的分段上传）, objects（匹配临时文件模式的对象）, versions（非当前版本和孤立的删除标记）
+	// Cleaning target: uploads (incomplete multipart uploads), objects (objects matching temporary file patterns),
+	// versions (noncurrent versions and orphaned delete markers)
+	Target string
+
+	// Presets objects 模式下使用的临时文件预设：spark, s3a, tmp, part, rclone
+	// Temporary file presets used in objects mode: spark, s3a, tmp, part, rclone
+	Presets []string
+
+	// Patterns objects 模式下使用的自定义临时文件模式，如 '*.bak' 或 'staging/'
+	// Custom temporary file patterns used in objects mode, e.g. '*.bak' or 'staging/'
+	Patterns []string
+
+	// KeepVersions versions 模式下每个对象保留的最新非当前版本数
+	// Number of newest noncurrent versions to keep per object in versions mode
+	KeepVersions int
+
+	// SortBy 报告的排序方式：size, age, key, bucket，也决定删除的先后顺序
+	// Sort order of the report: size, age, key, bucket, which also decides the deletion order
+	SortBy string
+
+	// Reverse 是否反转排序
+	// Whether to reverse the sort order
+	Reverse bool
+
+	// Top 只输出前N条记录，0 表示全部输出
+	// Only output the first N rows, 0 means all
+	Top int
+
+	// MaxDeleteCount 一次运行最多删除的文件数，0 表示不限制
+	// Maximum number of files to delete in one run, 0 means unlimited
+	MaxDeleteCount int
+
+	// MaxDeleteBytes 一次运行最多删除的容量，如 '100GiB'，为空表示不限制
+	// Maximum size to delete in one run, e.g. '100GiB', empty means unlimited
+	MaxDeleteBytes string
+
+	// Prefix lifecycle 规则的前缀过滤，为空表示整个桶
+	// Prefix filter of the lifecycle rule, empty means the whole bucket
+	Prefix string
+
+	// DryRun 只显示将要进行的修改，不实际执行
+	// Only show the changes that would be made without applying them
+	DryRun bool
+
+	// AbortFrom abort 子命令读取的上传列表文件，- 表示标准输入
+	// Upload list file read by the abort subcommand, - means standard input
+	AbortFrom string
+
+	// AbortStaleOnly abort 子命令只中止列表中 ShouldDelete 为 true 或未给出的行
+	// Only abort the rows of the list whose ShouldDelete is true or absent in the abort subcommand
+	AbortStaleOnly bool
+
+	// GroupBy 报告的分组方式：bucket, prefix:N, initiator, ageBucket，为空表示不分组
+	// How to group the report: bucket, prefix:N, initiator, ageBucket, empty means no grouping
+	GroupBy string
+
+	// GroupsFile 以CSV格式写入分组汇总的文件，为空表示不写入
+	// File the group summary is written to in CSV format, empty means disabled
+	GroupsFile string
+
+	// Checkpoint 记录扫描进度的检查点文件，中断后再次运行时从记录的位置继续，为空表示不使用
+	// Checkpoint file recording the scan progress, so an interrupted run continues from there, empty means disabled
+	Checkpoint string
+
+	// Progress stderr 上的进度输出方式：auto（stderr 是终端时输出进度行）, line, json, none
+	// Progress mode on stderr: auto (a progress line when stderr is a terminal), line, json, none
+	Progress string
+
+	// LogLevel stderr 上的日志级别：debug, info, warn, error
+	// Log level on stderr: debug, info, warn, error
+	LogLevel string
+
+	// LogFormat 日志格式：text, json
+	// Log format: text, json
+	LogFormat string
+
+	// MetricsFile 以 node_exporter textfile 格式写入指标的文件路径，为空表示不写入
+	// Path of the node_exporter textfile metrics file, empty means disabled
+	MetricsFile string
+
+	// Pushgateway Prometheus Pushgateway 地址，为空表示不推送
+	// Prometheus Pushgateway URL, empty means disabled
+	Pushgateway string
+
+	// Notify 每次运行后发送摘要的通知目标，格式为 kind=url，kind 为 webhook, slack, dingtalk, feishu, wecom
+	// Notification targets receiving the summary after each run, formatted as kind=url where kind is webhook, slack, dingtalk, feishu, wecom
+	Notify []string
+
+	// NotifyTemplate 通知消息的 text/template 模板文件，为空表示使用默认模板
+	// text/template file of the notification message, empty means the default template
+	NotifyTemplate string
+
+	// NotifySkipEmpty 没有发现过期文件且没有失败的桶时不发送通知
+	// Skip the notification when no stale files were found and no bucket failed
+	NotifySkipEmpty bool
+
+	// Initiators 和 ExcludeInitiators 只包含或排除这些发起者的上传，按名称或ID匹配
+	// Only include or exclude uploads of these initiators, matched by display name or ID
+	Initiators        []string
+	ExcludeInitiators []string
+
+	// Owners 和 ExcludeOwners 只包含或排除这些所有者的上传，按名称或ID匹配
+	// Only include or exclude uploads of these owners, matched by display name or ID
+	Owners        []string
+	ExcludeOwners []string
+
+	// Where 过滤表达式，如 size > 1GiB && age > 3d && key matches "^tmp/"
+	// Filter expression, e.g. size > 1GiB && age > 3d && key matches "^tmp/"
+	Where string
+
+	// Policy YAML 策略文件路径，按顺序匹配的规则决定每个文件保留还是按各自的时长清理
+	// Path of the YAML policy file, whose ordered rules decide whether each file is kept or cleaned after its own duration
+	Policy string
+
+	// ConfigFile YAML 配置文件路径，包含 SMTP 和价格设置
+	// Path of the YAML config file, which holds the SMTP and pricing settings
+	ConfigFile string
+
+	// MailTo 每次运行后接收邮件报告的地址
+	// Addresses receiving the email report after each run
+	MailTo []string
+
+	// SMTP 从配置文件读取的 SMTP 设置
+	// SMTP settings read from the config file
+	SMTP SMTPConfig
+
+	// Pricing 从配置文件读取的价格设置
+	// Pricing settings read from the config file
+	Pricing PricingConfig
+
+	// Schedule 守护进程模式下的 cron 调度表达式，如 "0 3 * * *"
+	// Cron schedule expression in daemon mode, e.g. "0 3 * * *"
+	Schedule string
+
+	// Interval 守护进程模式下的固定运行间隔，与 Schedule 二选一
+	// Fixed run interval in daemon mode, mutually exclusive with Schedule
+	Interval time.Duration
+
+	// Jitter 每次运行前随机延迟的最大时长，避免多个实例同时运行
+	// Maximum random delay before each run, so that multiple instances do not run at the same time
+	Jitter time.Duration
+
+	// Listen 守护进程模式下 HTTP 服务的监听地址
+	// Listen address of the HTTP server in daemon mode
+	Listen string
+
+	// RunOnStart 守护进程启动时是否立即运行一次
+	// Whether to run once immediately when the daemon starts
+	RunOnStart bool
+
+	// MaxRetries 每个S3请求在首次尝试之外的最大重试次数
+	// Maximum number of retries of each S3 request after the first attempt
+	MaxRetries int
+
+	// RetryMode 重试模式：standard, adaptive（根据限流情况自动降低请求速率）
+	// Retry mode: standard, adaptive (automatically lowers the request rate when throttled)
+	RetryMode string
+
+	// RequestTimeout 每次S3请求尝试的超时时间，0 表示不限制
+	// Timeout of each S3 request attempt, 0 means no timeout
+	RequestTimeout time.Duration
+
+	// ConnectTimeout 建立连接的超时时间，0 表示不限制
+	// Timeout of establishing a connection, 0 means no timeout
+	ConnectTimeout time.Duration
 }
 
-// ParseTime 解析时间字符串为时间对象
-// ParseTime parses time string to time object
-func (c *Config) ParseTime() error {
-	// 正则表达式匹配时间格式，如 7d, 72h
-	// Regular expression to match time format, e.g. 7d, 72h
-	re := regexp.MustCompile(`^(\d+)([dh])$`)
-	matches := re.FindStringSubmatch(c.Time)
+// BucketList 返回 --bucket 和 --bucketsFrom 指定的所有存储桶名称或 glob 模式；文件中的空行和 # 开头的注释行会被忽略
+// BucketList returns all bucket names or glob patterns given by --bucket and --bucketsFrom; empty lines and
+// comment lines starting with # in the file are ignored
+func (c *Config) BucketList() ([]string, error) {
+	buckets := slices.Clone(c.Buckets)
+	if c.BucketsFrom == "" {
+		return buckets, nil
+	}
 
-	if len(matches) != 3 {
-		return fmt.Errorf("无效的时间格式 '%s'，有效格式为: 数字+单位，如 '7d'（7天）或 '72h'（72小时）\nInvalid time format '%s', valid format is: number+unit, e.g. '7d' (7 days) or '72h' (72 hours)", c.Time, c.Time)
+	var r io.Reader = os.Stdin
+	if c.BucketsFrom != "-" {
+		f, err := os.Open(c.BucketsFrom)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取桶列表文件: %v\nFailed to read bucket list file: %v", err, err)
+		}
+		defer f.Close()
+		r = f
 	}
 
-	value, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return fmt.Errorf("无效的时间值 '%s'\nInvalid time value '%s'", matches[1], matches[1])
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		buckets = append(buckets, line)
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("无法读取桶列表: %v\nFailed to read bucket list: %v", err, err)
+	}
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("--bucketsFrom %s 中没有任何桶\n--bucketsFrom %s contains no buckets", c.BucketsFrom, c.BucketsFrom)
+	}
+	return buckets, nil
+}
 
-	unit := matches[2]
-	now := time.Now()
+// OlderThan 解析时间字符串为时长
+// OlderThan parses the time string to a duration
+func (c *Config) OlderThan() (time.Duration, error) {
+	return units.ParseDuration(c.Time)
+}
 
-	switch unit {
-	case "d":
-		c.ExpirationTime = now.AddDate(0, 0, -value)
-	case "h":
-		c.ExpirationTime = now.Add(-time.Duration(value) * time.Hour)
-	default:
-		return fmt.Errorf("无效的时间单位 '%s'，有效单位为: d（天）, h（小时）\nInvalid time unit '%s', valid units are: d (days), h (hours)", unit, unit)
+// Location 解析输出时间使用的时区
+// Location parses the time zone of the output times
+func (c *Config) Location() (*time.Location, error) {
+	switch c.TimeZone {
+	case "", "local", "Local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("无效的时区 '%s'，有效选项为: local, UTC 或 IANA 时区名称如 Asia/Shanghai\nInvalid time zone '%s', valid options are: local, UTC or an IANA zone name such as Asia/Shanghai", c.TimeZone, c.TimeZone)
 	}
+	return loc, nil
+}
 
-	return nil
+// OlderThanDays 返回时间字符串对应的天数，不足一天的部分按一天计算
+// OlderThanDays returns the number of days of the time string, rounding partial days up
+func (c *Config) OlderThanDays() (int, error) {
+	return units.ParseDays(c.Time)
+}
+
+// DeleteBytesLimit 解析最大删除容量，为空时返回 0（不限制）
+// DeleteBytesLimit parses the maximum size to delete, returning 0 (unlimited) if empty
+func (c *Config) DeleteBytesLimit() (int64, error) {
+	if c.MaxDeleteBytes == "" {
+		return 0, nil
+	}
+	return units.ParseSize(c.MaxDeleteBytes)
 }
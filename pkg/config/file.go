@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SMTP 加密方式
+// SMTP encryption modes
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+// SMTPConfig 发送邮件报告的 SMTP 设置
+// SMTPConfig holds the SMTP settings for email reports
+type SMTPConfig struct {
+	// Host 和 Port SMTP 服务器地址和端口
+	// Address and port of the SMTP server
+	Host string `yaml:"host"`
+	Port int    `yaml:"port"`
+
+	// Username 和 Password 认证信息，Username 为空表示不认证；Password 可以用环境变量 S4_SMTP_PASSWORD 代替
+	// Authentication, an empty Username means no authentication; Password may be given in the S4_SMTP_PASSWORD environment variable instead
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+
+	// From 发件人地址
+	// Sender address
+	From string `yaml:"from"`
+
+	// TLS 加密方式：starttls（默认）, tls（隐式 TLS，通常为 465 端口）, none
+	// Encryption mode: starttls (default), tls (implicit TLS, usually port 465), none
+	TLS string `yaml:"tls"`
+}
+
+// PricingConfig 估算存储成本的价格设置，未设置的部分使用缤纷云S4的默认价格
+// PricingConfig holds the prices for storage cost estimation, anything unset uses the Bitiful S4 defaults
+type PricingConfig struct {
+	// Currency 价格的货币单位
+	// Currency of the prices
+	Currency string `yaml:"currency"`
+
+	// Default 未列出的存储类型每 GB 每月的价格
+	// Price per GB-month of storage classes that are not listed
+	Default *float64 `yaml:"default"`
+
+	// Classes 各存储类型每 GB 每月的价格，如 STANDARD_IA: 0.08
+	// Price per GB-month of each storage class, e.g. STANDARD_IA: 0.08
+	Classes map[string]float64 `yaml:"classes"`
+}
+
+// File 配置文件的内容
+// File is the content of the config file
+type File struct {
+	SMTP    SMTPConfig    `yaml:"smtp"`
+	Pricing PricingConfig `yaml:"pricing"`
+}
+
+// LoadFile 读取 YAML 配置文件并填充默认值，未知的字段视为错误
+// LoadFile reads the YAML config file and fills in defaults, unknown fields are errors
+func LoadFile(path string) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取配置文件: %v\nFailed to read config file: %v", err, err)
+	}
+	defer f.Close()
+
+	file := &File{}
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("无法解析配置文件 %s: %v\nFailed to parse config file %s: %v", path, err, path, err)
+	}
+
+	smtp := &file.SMTP
+	if smtp.TLS == "" {
+		smtp.TLS = SMTPStartTLS
+	}
+	switch smtp.TLS {
+	case SMTPStartTLS, SMTPNone:
+		if smtp.Port == 0 {
+			smtp.Port = 587
+		}
+	case SMTPTLS:
+		if smtp.Port == 0 {
+			smtp.Port = 465
+		}
+	default:
+		return nil, fmt.Errorf("无效的 SMTP 加密方式 '%s'，有效选项为: starttls, tls, none\nInvalid SMTP encryption mode '%s', valid options are: starttls, tls, none", smtp.TLS, smtp.TLS)
+	}
+	if password := os.Getenv("S4_SMTP_PASSWORD"); password != "" {
+		smtp.Password = password
+	}
+
+	pricing := &file.Pricing
+	if pricing.Default != nil && *pricing.Default < 0 {
+		return nil, fmt.Errorf("无效的默认价格 %v，不能为负数\nInvalid default price %v, must not be negative", *pricing.Default, *pricing.Default)
+	}
+	for class, price := range pricing.Classes {
+		if price < 0 {
+			return nil, fmt.Errorf("存储类型 %s 的价格 %v 无效，不能为负数\nInvalid price %v of storage class %s, must not be negative", class, price, price, class)
+		}
+	}
+
+	return file, nil
+}
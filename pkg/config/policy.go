@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/units"
+	"gopkg.in/yaml.v3"
+)
+
+// PolicyRule 策略文件中的一条规则，容量和时间已解析
+// PolicyRule is one rule of the policy file, with sizes and durations parsed
+type PolicyRule struct {
+	Name      string
+	Bucket    string
+	Prefix    string
+	Regex     string
+	MinSize   int64
+	MaxSize   int64
+	Initiator string
+	Action    string
+	OlderThan time.Duration
+}
+
+// policyFile 策略文件的内容
+// policyFile is the content of the policy file
+type policyFile struct {
+	Rules []struct {
+		Name      string `yaml:"name"`
+		Bucket    string `yaml:"bucket"`
+		Prefix    string `yaml:"prefix"`
+		Regex     string `yaml:"regex"`
+		MinSize   string `yaml:"minSize"`
+		MaxSize   string `yaml:"maxSize"`
+		Initiator string `yaml:"initiator"`
+		Action    string `yaml:"action"`
+		OlderThan string `yaml:"olderThan"`
+	} `yaml:"rules"`
+}
+
+// LoadPolicy 读取 YAML 策略文件，按顺序返回其中的规则；未知的字段视为错误，abort 规则必须指定 olderThan
+// LoadPolicy reads the YAML policy file and returns its rules in order; unknown fields are errors
+// and abort rules must set olderThan
+func LoadPolicy(path string) ([]PolicyRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取策略文件: %v\nFailed to read policy file: %v", err, err)
+	}
+	defer f.Close()
+
+	var file policyFile
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("无法解析策略文件 %s: %v\nFailed to parse policy file %s: %v", path, err, path, err)
+	}
+	if len(file.Rules) == 0 {
+		return nil, fmt.Errorf("策略文件 %s 中没有任何规则\nPolicy file %s contains no rules", path, path)
+	}
+
+	rules := make([]PolicyRule, 0, len(file.Rules))
+	for i, raw := range file.Rules {
+		rule := PolicyRule{
+			Name:      raw.Name,
+			Bucket:    raw.Bucket,
+			Prefix:    raw.Prefix,
+			Regex:     raw.Regex,
+			Initiator: raw.Initiator,
+			Action:    raw.Action,
+		}
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i+1)
+		}
+
+		if raw.MinSize != "" {
+			if rule.MinSize, err = units.ParseSize(raw.MinSize); err != nil {
+				return nil, fmt.Errorf("规则 %s | Rule %s: %w", rule.Name, rule.Name, err)
+			}
+		}
+		if raw.MaxSize != "" {
+			if rule.MaxSize, err = units.ParseSize(raw.MaxSize); err != nil {
+				return nil, fmt.Errorf("规则 %s | Rule %s: %w", rule.Name, rule.Name, err)
+			}
+		}
+		if raw.OlderThan != "" {
+			if rule.OlderThan, err = units.ParseDuration(raw.OlderThan); err != nil {
+				return nil, fmt.Errorf("规则 %s | Rule %s: %w", rule.Name, rule.Name, err)
+			}
+		} else if rule.Action == "abort" {
+			return nil, fmt.Errorf("abort 规则 %s 必须指定 olderThan\nThe abort rule %s must set olderThan", rule.Name, rule.Name)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
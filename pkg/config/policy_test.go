@@ -0,0 +1,79 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPolicy(t *testing.T) {
+	path := writePolicy(t, `
+rules:
+  - name: keep-backups
+    bucket: "backup-*"
+    action: keep
+  - prefix: tmp/
+    regex: '\.part$'
+    minSize: 1MiB
+    maxSize: 1GiB
+    initiator: alice
+    action: abort
+    olderThan: 3d
+`)
+	rules, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PolicyRule{
+		{Name: "keep-backups", Bucket: "backup-*", Action: "keep"},
+		{Name: "#2", Prefix: "tmp/", Regex: `\.part$`, MinSize: 1 << 20, MaxSize: 1 << 30, Initiator: "alice", Action: "abort", OlderThan: 3 * 24 * time.Hour},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("rules = %+v, want %+v", rules, want)
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+}
+
+func TestLoadPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no rules", "rules: []\n", "contains no rules"},
+		{"unknown field", "rules:\n  - action: keep\n    olderthan: 3d\n", "olderthan"},
+		{"abort without olderThan", "rules:\n  - name: tmp\n    action: abort\n", "The abort rule tmp must set olderThan"},
+		{"invalid size", "rules:\n  - action: keep\n    minSize: 1XB\n", "Rule #1"},
+		{"invalid duration", "rules:\n  - action: abort\n    olderThan: 3w\n", "Rule #1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadPolicy(writePolicy(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadPolicy() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+	"github.com/robfig/cron/v3"
+)
+
+// readHeaderTimeout 读取 HTTP 请求头的超时时间，防止慢速客户端占用连接
+// readHeaderTimeout is the timeout for reading HTTP request headers, so slow clients cannot hold connections
+const readHeaderTimeout = 10 * time.Second
+
+// RunFunc 运行一次清理并返回结果
+// RunFunc runs the cleaner once and returns the result
+type RunFunc func(ctx context.Context) (*cleaner.Result, error)
+
+// Options 守护进程的选项
+// Options configures the daemon
+type Options struct {
+	// Schedule cron 调度表达式，与 Interval 二选一
+	// Cron schedule expression, mutually exclusive with Interval
+	Schedule string
+
+	// Interval 固定运行间隔，不能小于1秒
+	// Fixed run interval, must not be less than 1 second
+	Interval time.Duration
+
+	// Jitter 每次运行前随机延迟的最大时长，0 表示不延迟
+	// Maximum random delay before each run, 0 means no delay
+	Jitter time.Duration
+
+	// Listen HTTP 服务监听地址
+	// HTTP server listen address
+	Listen string
+
+	// RunOnStart 启动时立即运行一次
+	// Run once immediately on start
+	RunOnStart bool
+
+	// Logger 记录守护进程的启动、调度和失败的运行，为 nil 时不记录日志
+	// Logger records the daemon start, the schedule and failed runs, nil disables logging
+	Logger *slog.Logger
+}
+
+// Daemon 按计划周期性运行清理器的守护进程
+// Daemon runs the cleaner periodically according to a schedule
+type Daemon struct {
+	run      RunFunc
+	opts     Options
+	schedule cron.Schedule
+	log      *slog.Logger
+
+	mu         sync.RWMutex
+	lastResult *cleaner.Result
+	lastError  error
+	lastRunAt  time.Time
+	nextRunAt  time.Time
+	runs       int
+	failures   int
+}
+
+// New 创建新的守护进程
+// New creates a new daemon
+func New(run RunFunc, opts Options) (*Daemon, error) {
+	schedule, err := parseSchedule(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未指定日志记录器时丢弃日志
+	// Discard logs when no logger is given
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+
+	return &Daemon{
+		run:      run,
+		opts:     opts,
+		schedule: schedule,
+		log:      logger,
+	}, nil
+}
+
+// parseSchedule 根据选项解析调度计划
+// parseSchedule parses the schedule from options
+func parseSchedule(opts Options) (cron.Schedule, error) {
+	switch {
+	case opts.Schedule != "" && opts.Interval > 0:
+		return nil, fmt.Errorf("--schedule 和 --interval 只能指定一个\nOnly one of --schedule and --interval can be specified")
+	case opts.Schedule != "":
+		schedule, err := cron.ParseStandard(opts.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("无效的调度表达式 '%s': %v\nInvalid schedule expression '%s': %v", opts.Schedule, err, opts.Schedule, err)
+		}
+		return schedule, nil
+	case opts.Interval > 0:
+		if opts.Interval < time.Second {
+			return nil, fmt.Errorf("运行间隔不能小于1秒\nInterval must not be less than 1 second")
+		}
+		return cron.Every(opts.Interval), nil
+	default:
+		return nil, fmt.Errorf("必须指定 --schedule 或 --interval\nEither --schedule or --interval must be specified")
+	}
+}
+
+// Run 启动 HTTP 服务并按计划运行清理，直到 ctx 被取消
+// Run starts the HTTP server and runs the cleaner on schedule until ctx is cancelled
+func (d *Daemon) Run(ctx context.Context) error {
+	// 先监听端口，地址无效或被占用时立即返回错误，而不是在第一次运行之后
+	// Listen first so an invalid or busy address fails immediately instead of after the first run
+	listener, err := net.Listen("tcp", d.opts.Listen)
+	if err != nil {
+		return fmt.Errorf("无法监听 %s: %v\nFailed to listen on %s: %v", d.opts.Listen, err, d.opts.Listen, err)
+	}
+
+	server := &http.Server{
+		Handler:           d.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("HTTP 服务出错: %v\nHTTP server error: %v", err, err)
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	d.log.Info("守护进程已启动 | Daemon started", "listen", listener.Addr().String())
+
+	if d.opts.RunOnStart {
+		d.runOnce(ctx)
+	}
+
+	for {
+		next := d.nextRun(time.Now())
+		d.mu.Lock()
+		d.nextRunAt = next
+		d.mu.Unlock()
+
+		d.log.Info("等待下次运行 | Waiting for the next run", "next_run", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case err := <-serverErr:
+			timer.Stop()
+			return err
+		case <-timer.C:
+			d.runOnce(ctx)
+		}
+	}
+}
+
+// nextRun 计算下次运行时间，并加上随机抖动
+// nextRun calculates the next run time with random jitter added
+func (d *Daemon) nextRun(now time.Time) time.Time {
+	next := d.schedule.Next(now)
+	if d.opts.Jitter > 0 {
+		next = next.Add(rand.N(d.opts.Jitter))
+	}
+	return next
+}
+
+// runOnce 运行一次清理并保存结果
+// runOnce runs the cleaner once and keeps the result
+func (d *Daemon) runOnce(ctx context.Context) {
+	result, err := d.run(ctx)
+	if err != nil {
+		d.log.Error("运行失败 | Run failed", "error", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.runs++
+	d.lastRunAt = time.Now()
+	d.lastError = err
+	if err != nil {
+		d.failures++
+	}
+	if result != nil {
+		d.lastResult = result
+	}
+}
+
+// Handler 返回守护进程的 HTTP 处理器
+// Handler returns the HTTP handler of the daemon
+func (d *Daemon) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", d.handleHealthz)
+	mux.HandleFunc("/metrics", d.handleMetrics)
+	mux.HandleFunc("/last-report", d.handleLastReport)
+	return mux
+}
+
+// handleHealthz 返回守护进程的健康状态
+// handleHealthz returns the health status of the daemon
+func (d *Daemon) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	status := struct {
+		Status    string     `json:"status"`
+		Runs      int        `json:"runs"`
+		Failures  int        `json:"failures"`
+		LastRun   *time.Time `json:"last_run,omitempty"`
+		LastError string     `json:"last_error,omitempty"`
+		NextRun   *time.Time `json:"next_run,omitempty"`
+	}{
+		Status:   "ok",
+		Runs:     d.runs,
+		Failures: d.failures,
+	}
+	if !d.lastRunAt.IsZero() {
+		lastRun := d.lastRunAt
+		status.LastRun = &lastRun
+	}
+	if d.lastError != nil {
+		status.LastError = d.lastError.Error()
+	}
+	if !d.nextRunAt.IsZero() {
+		nextRun := d.nextRunAt
+		status.NextRun = &nextRun
+	}
+	d.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleMetrics 以 Prometheus 文本格式返回最近一次运行的指标
+// handleMetrics returns the metrics of the last run in Prometheus text format
+func (d *Daemon) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprintf(w, "# HELP s4_cleaner_daemon_runs_total Number of runs since the daemon started.\n# TYPE s4_cleaner_daemon_runs_total counter\ns4_cleaner_daemon_runs_total %d\n", d.runs)
+	fmt.Fprintf(w, "# HELP s4_cleaner_daemon_run_failures_total Number of failed runs since the daemon started.\n# TYPE s4_cleaner_daemon_run_failures_total counter\ns4_cleaner_daemon_run_failures_total %d\n", d.failures)
+	if !d.nextRunAt.IsZero() {
+		fmt.Fprintf(w, "# HELP s4_cleaner_daemon_next_run_timestamp_seconds Unix timestamp of the next scheduled run.\n# TYPE s4_cleaner_daemon_next_run_timestamp_seconds gauge\ns4_cleaner_daemon_next_run_timestamp_seconds %d\n", d.nextRunAt.Unix())
+	}
+	if d.lastResult != nil {
+		render.WriteMetrics(w, d.lastResult)
+	}
+}
+
+// handleLastReport 以JSON格式返回最近一次运行的结果
+// handleLastReport returns the result of the last run in JSON format
+func (d *Daemon) handleLastReport(w http.ResponseWriter, r *http.Request) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.lastResult == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no run has completed yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, d.lastResult)
+}
+
+// writeJSON 写入JSON响应
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// testResult 返回一个桶中有一个过期上传的运行结果
+// testResult returns the result of a run with one stale upload in one bucket
+func testResult() *cleaner.Result {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Target:    cleaner.TargetUploads,
+		Buckets:   []string{"a"},
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", UploadID: "u1", Size: 2048, ModTime: start.Add(-48 * time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+		},
+	}
+	result.Statistics = cleaner.ComputeStatistics(result.Files)
+	return result
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 30, 0, time.UTC)
+	tests := []struct {
+		name     string
+		opts     Options
+		earliest time.Time
+		latest   time.Time
+	}{
+		{"interval", Options{Interval: time.Hour}, now.Add(time.Hour), now.Add(time.Hour)},
+		{"schedule", Options{Schedule: "0 * * * *"}, now.Add(59*time.Minute + 30*time.Second), now.Add(59*time.Minute + 30*time.Second)},
+		{"interval with jitter", Options{Interval: time.Hour, Jitter: 10 * time.Minute}, now.Add(time.Hour), now.Add(time.Hour + 10*time.Minute - time.Nanosecond)},
+		{"schedule with jitter", Options{Schedule: "@daily", Jitter: time.Hour}, now.Add(11*time.Hour + 59*time.Minute + 30*time.Second), now.Add(12*time.Hour + 59*time.Minute + 30*time.Second - time.Nanosecond)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := New(nil, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 100 {
+				if next := d.nextRun(now); next.Before(tt.earliest) || next.After(tt.latest) {
+					t.Fatalf("nextRun() = %s, want between %s and %s", next, tt.earliest, tt.latest)
+				}
+			}
+		})
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"neither", Options{}, "Either --schedule or --interval"},
+		{"both", Options{Schedule: "@hourly", Interval: time.Hour}, "Only one of --schedule and --interval"},
+		{"short interval", Options{Interval: time.Millisecond}, "must not be less than 1 second"},
+		{"invalid schedule", Options{Schedule: "every day"}, "Invalid schedule expression 'every day'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(nil, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("New() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	runErr := errors.New("bucket listing failed")
+	results := []struct {
+		result *cleaner.Result
+		err    error
+	}{
+		{testResult(), nil},
+		{nil, runErr},
+	}
+	d, err := New(func(ctx context.Context) (*cleaner.Result, error) {
+		next := results[0]
+		results = results[1:]
+		return next.result, next.err
+	}, Options{Interval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		d.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	// 尚未运行时没有报告 | No report before the first run
+	if w := get("/last-report"); w.Code != http.StatusNotFound {
+		t.Errorf("/last-report status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+	if w := get("/metrics"); strings.Contains(w.Body.String(), "s4_cleaner_stale_uploads") {
+		t.Errorf("/metrics has run metrics before the first run:\n%s", w.Body.String())
+	}
+
+	// 第二次运行失败时保留第一次的结果 | A failed second run keeps the result of the first
+	d.runOnce(context.Background())
+	d.runOnce(context.Background())
+	d.nextRunAt = time.Date(2025, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	w := get("/healthz")
+	if w.Code != http.StatusOK {
+		t.Fatalf("/healthz status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var health struct {
+		Status    string     `json:"status"`
+		Runs      int        `json:"runs"`
+		Failures  int        `json:"failures"`
+		LastRun   *time.Time `json:"last_run"`
+		LastError string     `json:"last_error"`
+		NextRun   *time.Time `json:"next_run"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "ok" || health.Runs != 2 || health.Failures != 1 || health.LastRun == nil || health.LastError != runErr.Error() || health.NextRun == nil || !health.NextRun.Equal(d.nextRunAt) {
+		t.Errorf("/healthz = %+v, want 2 runs, 1 failure, the last error and the next run", health)
+	}
+
+	w = get("/metrics")
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain; version=0.0.4") {
+		t.Errorf("/metrics Content-Type = %q, want the Prometheus text format", got)
+	}
+	for _, want := range []string{
+		"s4_cleaner_daemon_runs_total 2\n",
+		"s4_cleaner_daemon_run_failures_total 1\n",
+		"s4_cleaner_daemon_next_run_timestamp_seconds 1748782800\n",
+		"s4_cleaner_stale_uploads",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("/metrics missing %q:\n%s", want, w.Body.String())
+		}
+	}
+
+	w = get("/last-report")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("/last-report status = %d with Content-Type %q, want a JSON report", w.Code, w.Header().Get("Content-Type"))
+	}
+	var report cleaner.Result
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 1 || report.Files[0].UploadID != "u1" {
+		t.Errorf("/last-report files = %+v, want the upload of the first run", report.Files)
+	}
+}
+
+func TestRunListenError(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	ran := false
+	d, err := New(func(ctx context.Context) (*cleaner.Result, error) {
+		ran = true
+		return testResult(), nil
+	}, Options{Interval: time.Hour, Listen: busy.Addr().String(), RunOnStart: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- d.Run(context.Background()) }()
+	select {
+	case err := <-errCh:
+		if err == nil || !strings.Contains(err.Error(), "Failed to listen on "+busy.Addr().String()) {
+			t.Errorf("Run() error = %v, want the listen error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return on a busy address")
+	}
+	if ran {
+		t.Error("the cleaner ran although the daemon could not listen")
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/config"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+)
+
+// mailTimeout 发送一封邮件的超时时间
+// mailTimeout is the timeout of sending one email
+const mailTimeout = time.Minute
+
+// Mailer 在每次运行后通过 SMTP 发送 HTML 报告和 CSV 附件
+// Mailer sends the HTML report with a CSV attachment over SMTP after each run
+type Mailer struct {
+	smtp   config.SMTPConfig
+	to     []string
+	report render.Options
+}
+
+// NewMailer 创建邮件发送器，报告使用 report 中的输出选项，其中的格式会被忽略；没有收件人时返回 nil
+// NewMailer creates a mailer whose reports use the output options of report, ignoring its format;
+// returns nil when there are no recipients
+func NewMailer(smtpCfg config.SMTPConfig, to []string, report render.Options) (*Mailer, error) {
+	if len(to) == 0 {
+		return nil, nil
+	}
+	if smtpCfg.Host == "" || smtpCfg.From == "" {
+		return nil, fmt.Errorf("--mailTo 需要在配置文件中设置 smtp.host 和 smtp.from\n--mailTo requires smtp.host and smtp.from in the config file")
+	}
+	for _, addr := range append([]string{smtpCfg.From}, to...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return nil, fmt.Errorf("无效的邮件地址 '%s': %v\nInvalid email address '%s': %v", addr, err, addr, err)
+		}
+	}
+	return &Mailer{smtp: smtpCfg, to: to, report: report}, nil
+}
+
+// Send 发送一次运行的报告，m 为 nil 时不做任何事
+// Send sends the report of one run, doing nothing if m is nil
+func (m *Mailer) Send(ctx context.Context, r *cleaner.Result, dryRun bool) error {
+	if m == nil {
+		return nil
+	}
+
+	message, err := m.message(r, dryRun)
+	if err != nil {
+		return err
+	}
+	if err := m.send(ctx, message); err != nil {
+		return fmt.Errorf("无法发送邮件报告: %v\nFailed to send email report: %v", err, err)
+	}
+	return nil
+}
+
+// message 生成包含 HTML 报告和 CSV 附件的邮件
+// message builds the email with the HTML report and the CSV attachment
+func (m *Mailer) message(r *cleaner.Result, dryRun bool) ([]byte, error) {
+	var html, csv bytes.Buffer
+	report := m.report
+	report.Format = "html"
+	if err := render.Result(&html, r, report); err != nil {
+		return nil, err
+	}
+	report.Format = "csv"
+	if err := render.Result(&csv, r, report); err != nil {
+		return nil, err
+	}
+
+	summary := NewSummary(r, dryRun)
+	subject := fmt.Sprintf("S4 Cleaner 报告 | Report (%s): %d 个过期文件 stale files (%s)", r.Target, summary.StaleFiles, render.FormatSizeUnits(summary.StaleBytes, m.report.SizeUnits))
+	if len(summary.FailedBuckets) > 0 {
+		subject += fmt.Sprintf(", %d 个桶失败 failed buckets", len(summary.FailedBuckets))
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "From: %s\r\n", m.smtp.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(m.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", messageID(m.smtp.From))
+	fmt.Fprintf(&buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", writer.Boundary())
+
+	// HTML 正文
+	// HTML body
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	qp := quotedprintable.NewWriter(part)
+	qp.Write(html.Bytes())
+	qp.Close()
+
+	// CSV 附件
+	// CSV attachment
+	filename := fmt.Sprintf("s4-cleaner-%s.csv", r.StartTime.Format("20060102-150405"))
+	part, err = writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/csv; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": filename})},
+	})
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(csv.Bytes())
+	for len(encoded) > 76 {
+		fmt.Fprintf(part, "%s\r\n", encoded[:76])
+		encoded = encoded[76:]
+	}
+	fmt.Fprintf(part, "%s\r\n", encoded)
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageID 生成邮件的 Message-ID
+// messageID generates the Message-ID of an email
+func messageID(from string) string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	domain := "s4-cleaner"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if _, d, ok := strings.Cut(addr.Address, "@"); ok {
+			domain = d
+		}
+	}
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
+
+// send 连接 SMTP 服务器并发送邮件；starttls 模式下服务器不支持 STARTTLS 时报错，而不是以明文发送
+// send connects to the SMTP server and sends the email; in starttls mode it fails instead of sending in plain text
+// when the server does not support STARTTLS
+func (m *Mailer) send(ctx context.Context, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, mailTimeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.smtp.Host, strconv.Itoa(m.smtp.Port))
+	tlsConfig := &tls.Config{ServerName: m.smtp.Host}
+
+	var conn net.Conn
+	var err error
+	if m.smtp.TLS == config.SMTPTLS {
+		conn, err = (&tls.Dialer{Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.smtp.TLS == config.SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("服务器 %s 不支持 STARTTLS\nServer %s does not support STARTTLS", addr, addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if m.smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.smtp.Username, m.smtp.Password, m.smtp.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := mail.ParseAddress(m.smtp.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range m.to {
+		rcpt, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
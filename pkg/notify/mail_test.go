@@ -0,0 +1,181 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package notify
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/config"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+)
+
+// sinkMail 测试 SMTP 服务器收到的一封邮件
+// sinkMail is one email received by the test SMTP server
+type sinkMail struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpSink 启动只接受一个连接的 SMTP 服务器，不支持 STARTTLS，返回端口和收到的邮件
+// smtpSink starts an SMTP server accepting one connection without STARTTLS support,
+// returning its port and the received mail
+func smtpSink(t *testing.T) (int, <-chan sinkMail) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan sinkMail, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+		tp := textproto.NewConn(conn)
+		var mail sinkMail
+		tp.PrintfLine("220 sink ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(line, " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 sink")
+			case "MAIL":
+				mail.from = strings.Trim(strings.TrimPrefix(arg, "FROM:"), "<>")
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				mail.to = append(mail.to, strings.Trim(strings.TrimPrefix(arg, "TO:"), "<>"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 Go ahead")
+				data, err := tp.ReadDotBytes()
+				if err != nil {
+					return
+				}
+				mail.data = string(data)
+				tp.PrintfLine("250 OK")
+				received <- mail
+			case "QUIT":
+				tp.PrintfLine("221 Bye")
+				return
+			default:
+				tp.PrintfLine("502 Not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, received
+}
+
+// testResult 返回一个桶中有一个过期上传的运行结果
+// testResult returns the result of a run with one stale upload in one bucket
+func testResult() *cleaner.Result {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Target:    cleaner.TargetUploads,
+		Buckets:   []string{"a"},
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", UploadID: "u1", Size: 2048, ModTime: start.Add(-48 * time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+		},
+	}
+	result.Statistics = cleaner.ComputeStatistics(result.Files)
+	return result
+}
+
+func TestMailerSend(t *testing.T) {
+	port, received := smtpSink(t)
+	mailer, err := NewMailer(config.SMTPConfig{
+		Host: "127.0.0.1",
+		Port: port,
+		TLS:  config.SMTPNone,
+		From: "S4 Cleaner <cleaner@example.com>",
+	}, []string{"ops@example.com", "Dev <dev@example.com>"}, render.Options{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mailer.Send(context.Background(), testResult(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	var mail sinkMail
+	select {
+	case mail = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+	}
+	if mail.from != "cleaner@example.com" {
+		t.Errorf("MAIL FROM = %q, want cleaner@example.com", mail.from)
+	}
+	if want := []string{"ops@example.com", "dev@example.com"}; !slices.Equal(mail.to, want) {
+		t.Errorf("RCPT TO = %v, want %v", mail.to, want)
+	}
+	for _, want := range []string{
+		"From: S4 Cleaner <cleaner@example.com>",
+		"To: ops@example.com, Dev <dev@example.com>",
+		"Content-Type: text/html; charset=utf-8",
+		"Content-Type: text/csv; charset=utf-8",
+		`filename=s4-cleaner-20250601-120000.csv`,
+	} {
+		if !strings.Contains(mail.data, want) {
+			t.Errorf("message missing %q:\n%s", want, mail.data)
+		}
+	}
+}
+
+func TestMailerSendRequiresStartTLS(t *testing.T) {
+	port, received := smtpSink(t)
+	mailer, err := NewMailer(config.SMTPConfig{
+		Host: "127.0.0.1",
+		Port: port,
+		TLS:  config.SMTPStartTLS,
+		From: "cleaner@example.com",
+	}, []string{"ops@example.com"}, render.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mailer.Send(context.Background(), testResult(), true)
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1:"+strconv.Itoa(port)+" does not support STARTTLS") {
+		t.Fatalf("Send() error = %v, want STARTTLS to be required", err)
+	}
+	select {
+	case mail := <-received:
+		t.Errorf("mail sent in plain text: %+v", mail)
+	default:
+	}
+}
+
+func TestNilMailerSend(t *testing.T) {
+	mailer, err := NewMailer(config.SMTPConfig{}, nil, render.Options{})
+	if mailer != nil || err != nil {
+		t.Fatalf("NewMailer(no recipients) = %v, %v, want nil, nil", mailer, err)
+	}
+	if err := mailer.Send(context.Background(), testResult(), true); err != nil {
+		t.Error(err)
+	}
+}
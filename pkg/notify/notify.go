@@ -0,0 +1,190 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/bitiful/s4-cleaner/pkg/render"
+)
+
+// 通知目标类型
+// Notification target kinds
+const (
+	KindWebhook  = "webhook"
+	KindSlack    = "slack"
+	KindDingTalk = "dingtalk"
+	KindFeishu   = "feishu"
+	KindWeCom    = "wecom"
+)
+
+// defaultTemplate 默认的消息模板
+// defaultTemplate is the default message template
+const defaultTemplate = `S4 Cleaner {{if .DryRun}}扫描 | Scan{{else}}清理 | Clean{{end}} ({{.Target}})
+桶 Buckets: {{.Buckets}}{{if .FailedBuckets}} | 失败的桶 Failed buckets: {{join .FailedBuckets ", "}}{{end}}
+过期 Stale: {{.StaleFiles}} ({{size .StaleBytes}})
+{{- if not .DryRun}}
+已删除 Aborted: {{.Aborted}} ({{size .AbortedBytes}}) | 删除失败 Failed: {{.Failed}}
+{{- end}}
+{{- if .TopPrefixes}}
+前缀 Top prefixes:
+{{- range .TopPrefixes}}
+  {{.Group}} {{.FilesToDelete}} ({{size .SizeToDelete}})
+{{- end}}
+{{- end}}`
+
+// target 一个通知目标
+// target is one notification target
+type target struct {
+	kind string
+	url  string
+}
+
+// Notifier 在每次运行后向通知目标发送摘要
+// Notifier posts the run summary to the notification targets after each run
+type Notifier struct {
+	targets   []target
+	tmpl      *template.Template
+	skipEmpty bool
+	client    *http.Client
+}
+
+// New 创建通知器，specs 的格式为 kind=url，templateFile 为空时使用默认模板，sizeUnits 为消息中容量的单位；没有通知目标时返回 nil
+// New creates a notifier, specs are formatted as kind=url, the default template is used when templateFile is empty
+// and sizeUnits are the units of the sizes in the message; returns nil when there are no targets
+func New(specs []string, templateFile string, skipEmpty bool, sizeUnits string) (*Notifier, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+
+	targets := make([]target, 0, len(specs))
+	for _, spec := range specs {
+		kind, url, ok := strings.Cut(spec, "=")
+		kind = strings.ToLower(kind)
+		if !ok || url == "" {
+			return nil, fmt.Errorf("无效的通知目标 '%s'，格式应为 kind=url\nInvalid notification target '%s', must be formatted as kind=url", spec, spec)
+		}
+		switch kind {
+		case KindWebhook, KindSlack, KindDingTalk, KindFeishu, KindWeCom:
+		default:
+			return nil, fmt.Errorf("无效的通知类型 '%s'，有效选项为: webhook, slack, dingtalk, feishu, wecom\nInvalid notification kind '%s', valid options are: webhook, slack, dingtalk, feishu, wecom", kind, kind)
+		}
+		targets = append(targets, target{kind: kind, url: url})
+	}
+
+	text := defaultTemplate
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取消息模板: %v\nFailed to read message template: %v", err, err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("message").Funcs(template.FuncMap{
+		"size": func(size int64) string { return render.FormatSizeUnits(size, sizeUnits) },
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析消息模板: %v\nFailed to parse message template: %v", err, err)
+	}
+
+	return &Notifier{
+		targets:   targets,
+		tmpl:      tmpl,
+		skipEmpty: skipEmpty,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Notify 向所有通知目标发送一次运行的摘要，n 为 nil 时不做任何事；单个目标失败不影响其他目标
+// Notify posts the summary of one run to all targets, doing nothing if n is nil; a failing target does not affect the others
+func (n *Notifier) Notify(ctx context.Context, r *cleaner.Result, dryRun bool) error {
+	if n == nil {
+		return nil
+	}
+
+	summary := NewSummary(r, dryRun)
+	if n.skipEmpty && summary.Empty() {
+		return nil
+	}
+
+	var message bytes.Buffer
+	if err := n.tmpl.Execute(&message, summary); err != nil {
+		return fmt.Errorf("无法生成通知消息: %v\nFailed to render notification message: %v", err, err)
+	}
+
+	var errs []error
+	for _, t := range n.targets {
+		if err := n.post(ctx, t, payload(t.kind, message.String(), summary)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// payload 按通知类型生成请求体
+// payload builds the request body for the target kind
+func payload(kind, message string, summary Summary) any {
+	switch kind {
+	case KindSlack:
+		return map[string]any{"text": message}
+	case KindDingTalk, KindWeCom:
+		return map[string]any{"msgtype": "text", "text": map[string]any{"content": message}}
+	case KindFeishu:
+		return map[string]any{"msg_type": "text", "content": map[string]any{"text": message}}
+	default: // webhook
+		return map[string]any{"text": message, "summary": summary}
+	}
+}
+
+// post 发送通知；钉钉、飞书和企业微信出错时也返回 200，因此还要检查响应中的错误码
+// post sends the notification; DingTalk, Feishu and WeCom also return 200 on errors, so the error code in the response is checked too
+func (n *Notifier) post(ctx context.Context, t target, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("无法序列化通知: %v\nFailed to serialize notification: %v", err, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("无法发送 %s 通知: %v\nFailed to send %s notification: %v", t.kind, err, t.kind, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("无法发送 %s 通知: %v\nFailed to send %s notification: %v", t.kind, err, t.kind, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	respBody = bytes.TrimSpace(respBody)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("无法发送 %s 通知: %s %s\nFailed to send %s notification: %s %s", t.kind, resp.Status, respBody, t.kind, resp.Status, respBody)
+	}
+
+	var result struct {
+		ErrCode int `json:"errcode"`
+		Code    int `json:"code"`
+	}
+	if json.Unmarshal(respBody, &result) == nil && (result.ErrCode != 0 || result.Code != 0) {
+		return fmt.Errorf("无法发送 %s 通知: %s\nFailed to send %s notification: %s", t.kind, respBody, t.kind, respBody)
+	}
+	return nil
+}
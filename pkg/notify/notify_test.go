@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// webhookSink 记录每个路径收到的请求体，并返回 responses 中该路径的响应；返回服务器地址和读取请求体的函数
+// webhookSink records the request body received on each path and answers with the response for that path in responses;
+// returns the server URL and a function reading the recorded bodies
+func webhookSink(t *testing.T, responses map[string]string) (string, func(path string) (map[string]any, bool)) {
+	t.Helper()
+	var mu sync.Mutex
+	bodies := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("%s: invalid JSON body %s", r.URL.Path, data)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", r.URL.Path, got)
+		}
+		mu.Lock()
+		bodies[r.URL.Path] = body
+		mu.Unlock()
+
+		if response, ok := responses[r.URL.Path]; ok {
+			status, text, _ := strings.Cut(response, " ")
+			if status == "500" {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			io.WriteString(w, text)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+	return server.URL, func(path string) (map[string]any, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, ok := bodies[path]
+		return body, ok
+	}
+}
+
+func TestNotifyPayloads(t *testing.T) {
+	url, body := webhookSink(t, map[string]string{
+		"/dingtalk": `200 {"errcode":0,"errmsg":"ok"}`,
+		"/feishu":   `200 {"code":0,"msg":"success"}`,
+		"/wecom":    `200 {"errcode":0,"errmsg":"ok"}`,
+	})
+	notifier, err := New([]string{
+		"webhook=" + url + "/webhook",
+		"slack=" + url + "/slack",
+		"DingTalk=" + url + "/dingtalk",
+		"feishu=" + url + "/feishu",
+		"wecom=" + url + "/wecom",
+	}, "", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), testResult(), true); err != nil {
+		t.Fatal(err)
+	}
+
+	bodies := map[string]map[string]any{}
+	for _, path := range []string{"/webhook", "/slack", "/dingtalk", "/feishu", "/wecom"} {
+		bodies[path], _ = body(path)
+	}
+	text := func(v any) string {
+		s, _ := v.(string)
+		return s
+	}
+	field := func(v any, key string) any {
+		m, _ := v.(map[string]any)
+		return m[key]
+	}
+	messages := map[string]string{
+		"/webhook":  text(bodies["/webhook"]["text"]),
+		"/slack":    text(bodies["/slack"]["text"]),
+		"/dingtalk": text(field(bodies["/dingtalk"]["text"], "content")),
+		"/feishu":   text(field(bodies["/feishu"]["content"], "text")),
+		"/wecom":    text(field(bodies["/wecom"]["text"], "content")),
+	}
+	for path, message := range messages {
+		if !strings.Contains(message, "过期 Stale: 1 (2.00 KiB)") {
+			t.Errorf("%s message = %q, want the run summary", path, message)
+		}
+	}
+	if bodies["/dingtalk"]["msgtype"] != "text" || bodies["/wecom"]["msgtype"] != "text" || bodies["/feishu"]["msg_type"] != "text" {
+		t.Errorf("message types = %v, %v, %v, want text", bodies["/dingtalk"]["msgtype"], bodies["/wecom"]["msgtype"], bodies["/feishu"]["msg_type"])
+	}
+	summary, _ := bodies["/webhook"]["summary"].(map[string]any)
+	if summary["target"] != "uploads" || summary["stale_files"] != float64(1) || summary["dry_run"] != true {
+		t.Errorf("webhook summary = %v, want the structured summary", summary)
+	}
+}
+
+func TestNotifyErrors(t *testing.T) {
+	url, _ := webhookSink(t, map[string]string{
+		"/dingtalk": `200 {"errcode":310000,"errmsg":"keywords not in content"}`,
+		"/feishu":   `200 {"code":19021,"msg":"sign match fail or timestamp is not within one hour from current time"}`,
+		"/wecom":    `200 {"errcode":93000,"errmsg":"invalid webhook url"}`,
+		"/webhook":  `500 internal error`,
+		"/slack":    `200 ok`,
+	})
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"dingtalk=" + url + "/dingtalk", "Failed to send dingtalk notification: {\"errcode\":310000"},
+		{"feishu=" + url + "/feishu", "Failed to send feishu notification: {\"code\":19021"},
+		{"wecom=" + url + "/wecom", "Failed to send wecom notification: {\"errcode\":93000"},
+		{"webhook=" + url + "/webhook", "Failed to send webhook notification: 500 Internal Server Error internal error"},
+		{"slack=" + url + "/slack", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec[:strings.Index(tt.spec, "=")], func(t *testing.T) {
+			notifier, err := New([]string{tt.spec}, "", false, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = notifier.Notify(context.Background(), testResult(), true)
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Notify() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Notify() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyOneTargetFailing(t *testing.T) {
+	url, body := webhookSink(t, map[string]string{"/dingtalk": `200 {"errcode":300001,"errmsg":"token is not exist"}`})
+	notifier, err := New([]string{"dingtalk=" + url + "/dingtalk", "slack=" + url + "/slack"}, "", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(context.Background(), testResult(), true); err == nil {
+		t.Error("Notify() error = nil, want the DingTalk error")
+	}
+	if _, ok := body("/slack"); !ok {
+		t.Error("Slack was not notified after DingTalk failed")
+	}
+}
+
+func TestNewNotifierErrors(t *testing.T) {
+	for _, spec := range []string{"webhook", "webhook=", "teams=https://example.com"} {
+		if _, err := New([]string{spec}, "", false, ""); err == nil {
+			t.Errorf("New(%q) succeeded, want an error", spec)
+		}
+	}
+}
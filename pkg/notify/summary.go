@@ -0,0 +1,95 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package notify
+
+import (
+	"slices"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// topPrefixCount 摘要中列出的前缀数量
+// topPrefixCount is the number of prefixes listed in the summary
+const topPrefixCount = 5
+
+// Summary 一次运行的摘要，也是消息模板的数据
+// Summary is the summary of one run, and also the data of the message template
+type Summary struct {
+	// Target 清理目标
+	// Cleaning target
+	Target string `json:"target"`
+
+	// DryRun 本次运行只扫描不删除
+	// The run only scanned without deleting
+	DryRun bool `json:"dry_run"`
+
+	// Buckets 成功扫描的桶数
+	// Number of buckets scanned successfully
+	Buckets int `json:"buckets"`
+
+	// FailedBuckets 处理失败的桶
+	// Buckets that failed
+	FailedBuckets []string `json:"failed_buckets"`
+
+	// StaleFiles 和 StaleBytes 过期文件的数量和容量
+	// Number and size of stale files
+	StaleFiles int   `json:"stale_files"`
+	StaleBytes int64 `json:"stale_bytes"`
+
+	// Aborted 和 AbortedBytes 已删除或中止的文件数量和容量
+	// Number and size of files deleted or aborted
+	Aborted      int   `json:"aborted"`
+	AbortedBytes int64 `json:"aborted_bytes"`
+
+	// Failed 删除失败的文件数
+	// Number of files that failed to delete
+	Failed int `json:"failed"`
+
+	// TopPrefixes 过期文件容量最大的前缀，格式为 bucket/prefix/
+	// Prefixes with the largest size of stale files, formatted as bucket/prefix/
+	TopPrefixes []cleaner.GroupSummary `json:"top_prefixes"`
+
+	// Duration 和 DurationSeconds 本次运行的耗时
+	// How long the run took
+	Duration        time.Duration `json:"-"`
+	DurationSeconds float64       `json:"duration_seconds"`
+}
+
+// NewSummary 根据一次运行的结果生成摘要
+// NewSummary builds the summary of one run from its result
+func NewSummary(r *cleaner.Result, dryRun bool) Summary {
+	stale := slices.DeleteFunc(slices.Clone(r.Files), func(file cleaner.FileInfo) bool {
+		return !file.ShouldDelete
+	})
+	prefixes, _ := cleaner.GroupFiles(stale, "prefix:1", r.StartTime)
+	if len(prefixes) > topPrefixCount {
+		prefixes = prefixes[:topPrefixCount]
+	}
+
+	return Summary{
+		Target:          r.Target,
+		DryRun:          dryRun,
+		Buckets:         len(r.Buckets),
+		FailedBuckets:   r.FailedBuckets,
+		StaleFiles:      r.Statistics.FilesToDelete,
+		StaleBytes:      r.Statistics.SizeToDelete,
+		Aborted:         r.Statistics.FilesDeleted,
+		AbortedBytes:    r.Statistics.SizeDeleted,
+		Failed:          r.Statistics.FilesFailed,
+		TopPrefixes:     prefixes,
+		Duration:        r.Duration(),
+		DurationSeconds: r.Duration().Seconds(),
+	}
+}
+
+// Empty 本次运行既没有发现过期文件，也没有失败的桶
+// Empty reports whether the run found no stale files and had no failed buckets
+func (s Summary) Empty() bool {
+	return s.StaleFiles == 0 && len(s.FailedBuckets) == 0
+}
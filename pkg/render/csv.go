@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// outputCSV 以CSV格式输出结果
+// outputCSV outputs results in CSV format
+func outputCSV(w io.Writer, r *cleaner.Result, opts Options) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	loc := opts.location()
+	showVersion := r.Target == cleaner.TargetVersions
+	showUpload := r.Target == cleaner.TargetUploads
+	showLastPart := r.AgeBasis == cleaner.AgeBasisLastPart
+	showRule := len(r.Rules) > 0
+
+	// 写入表头
+	// Write header
+	header := []string{"Bucket", "Key", "Size", "SizeFormatted", "ModTime", "AgeSeconds", "ShouldDelete", "DeleteSuccess", "StorageClass", "MonthlyCost", "AccumulatedCost"}
+	if showRule {
+		header = append(header, "Rule")
+	}
+	if showLastPart {
+		header = slices.Insert(header, 5, "LastPartTime")
+	}
+	if showVersion {
+		header = slices.Insert(header, 2, "VersionId")
+	}
+	if showUpload {
+		header = slices.Insert(header, 2, "UploadId")
+	}
+	if err := writer.Write(header); err != nil {
+		return fmt.Errorf("无法写入CSV表头: %v\nFailed to write CSV header: %v", err, err)
+	}
+
+	// 写入数据
+	// Write data
+	for _, file := range topFiles(r.Files, opts.Top) {
+		shouldDelete := "false"
+		if file.ShouldDelete {
+			shouldDelete = "true"
+		}
+
+		deleteSuccess := "not_executed"
+		if file.Skipped {
+			deleteSuccess = "skipped"
+		} else if file.DeleteSuccess != nil {
+			if *file.DeleteSuccess {
+				deleteSuccess = "true"
+			} else {
+				deleteSuccess = "false"
+			}
+		}
+
+		ageSeconds := ""
+		if age, ok := fileAge(file, r.StartTime); ok {
+			ageSeconds = strconv.FormatInt(int64(age/time.Second), 10)
+		}
+
+		row := []string{
+			file.Bucket,
+			file.Key,
+			fmt.Sprintf("%d", file.Size),
+			opts.formatSize(file.Size),
+			inLocation(file.ModTime, loc).Format(time.RFC3339),
+			ageSeconds,
+			shouldDelete,
+			deleteSuccess,
+			file.StorageClass,
+			strconv.FormatFloat(r.Pricing.MonthlyCost(file), 'f', 6, 64),
+			strconv.FormatFloat(r.Pricing.AccumulatedCost(file, r.StartTime), 'f', 6, 64),
+		}
+		if showRule {
+			row = append(row, file.Rule)
+		}
+		if showLastPart {
+			lastPart := ""
+			if file.LastPartTime != nil {
+				lastPart = file.LastPartTime.In(loc).Format(time.RFC3339)
+			}
+			row = slices.Insert(row, 5, lastPart)
+		}
+		if showVersion {
+			row = slices.Insert(row, 2, file.VersionID)
+		}
+		if showUpload {
+			row = slices.Insert(row, 2, file.UploadID)
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("无法写入CSV数据: %v\nFailed to write CSV data: %v", err, err)
+		}
+	}
+
+	return nil
+}
+
+// GroupsCSV 以CSV格式输出结果的分组汇总，与文件明细分开写入
+// GroupsCSV writes the group summary of the result in CSV format, separately from the file rows
+func GroupsCSV(w io.Writer, r *cleaner.Result, opts Options) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	// 写入表头
+	// Write header
+	if err := writer.Write([]string{"Group", "TotalFiles", "TotalSize", "FilesToDelete", "SizeToDelete", "FilesDeleted", "SizeDeleted", "FilesFailed", "SizeFailed",
+		"TotalSizeFormatted", "SizeToDeleteFormatted", "SizeDeletedFormatted", "SizeFailedFormatted"}); err != nil {
+		return fmt.Errorf("无法写入CSV表头: %v\nFailed to write CSV header: %v", err, err)
+	}
+
+	// 写入数据
+	// Write data
+	for _, group := range r.Groups {
+		if err := writer.Write([]string{
+			group.Group,
+			fmt.Sprintf("%d", group.TotalFiles),
+			fmt.Sprintf("%d", group.TotalSize),
+			fmt.Sprintf("%d", group.FilesToDelete),
+			fmt.Sprintf("%d", group.SizeToDelete),
+			fmt.Sprintf("%d", group.FilesDeleted),
+			fmt.Sprintf("%d", group.SizeDeleted),
+			fmt.Sprintf("%d", group.FilesFailed),
+			fmt.Sprintf("%d", group.SizeFailed),
+			opts.formatSize(group.TotalSize),
+			opts.formatSize(group.SizeToDelete),
+			opts.formatSize(group.SizeDeleted),
+			opts.formatSize(group.SizeFailed),
+		}); err != nil {
+			return fmt.Errorf("无法写入CSV数据: %v\nFailed to write CSV data: %v", err, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteGroupsFile 将分组汇总以CSV格式写入文件，路径为空时跳过
+// WriteGroupsFile writes the group summary to a file in CSV format, skipping when the path is empty
+func WriteGroupsFile(path string, r *cleaner.Result, opts Options) error {
+	if path == "" {
+		return nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("无法创建分组汇总文件: %v\nFailed to create group summary file: %v", err, err)
+	}
+	if err := GroupsCSV(file, r, opts); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("无法写入分组汇总文件: %v\nFailed to write group summary file: %v", err, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestCSVKeepsRowsWhenGrouped(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{
+		StartTime: start,
+		Target:    cleaner.TargetUploads,
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", UploadID: "u1", Size: 100, ModTime: start.Add(-time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+			{Bucket: "b", Key: "y.bin", UploadID: "u2", Size: 200, ModTime: start.Add(-time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+		},
+		Groups: []cleaner.GroupSummary{
+			{Group: "a", Statistics: cleaner.Statistics{TotalFiles: 1, TotalSize: 100, FilesToDelete: 1, SizeToDelete: 100, FilesFailed: 1, SizeFailed: 100}},
+		},
+	}
+	opts := Options{Location: time.UTC}
+
+	// 分组时明细行保持不变 | File rows are unchanged when grouping
+	var buf bytes.Buffer
+	if err := outputCSV(&buf, result, opts); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[0][0] != "Bucket" || rows[1][1] != "x.bin" || rows[2][1] != "y.bin" {
+		t.Fatalf("rows = %v, want the header and two file rows", rows)
+	}
+
+	// 分组汇总包含删除失败的统计 | The group summary includes the failed counts
+	buf.Reset()
+	if err := GroupsCSV(&buf, result, opts); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("groups = %v, want the header and one group", groups)
+	}
+	got := map[string]string{}
+	for i, name := range groups[0] {
+		got[name] = groups[1][i]
+	}
+	if got["Group"] != "a" || got["FilesFailed"] != "1" || got["SizeFailed"] != "100" {
+		t.Errorf("group row = %v, want group a with 1 failed file of 100 bytes", got)
+	}
+}
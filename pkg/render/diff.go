@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Diff 按 opts.Format 输出两份报告的差异，支持 table（默认）, json, csv，其他格式返回错误
+// Diff writes the difference between two reports in opts.Format, supporting table (default), json and csv
+// and returning an error for any other format
+func Diff(w io.Writer, diffs []cleaner.BucketDiff, opts Options) error {
+	switch strings.ToLower(opts.Format) {
+	case "", "table":
+		return outputDiffTable(w, diffs, opts)
+	case "json":
+		return outputDiffJSON(w, diffs)
+	case "csv":
+		return outputDiffCSV(w, diffs, opts)
+	default:
+		return unsupportedFormat(opts.Format, "报告差异", "report diffs", "table", "json", "csv")
+	}
+}
+
+// diffEntries 按新出现、已消失、仍然存在的顺序返回一个桶的所有差异
+// diffEntries returns all differences of a bucket in the order new, gone, stuck
+func diffEntries(d cleaner.BucketDiff) []cleaner.DiffEntry {
+	entries := make([]cleaner.DiffEntry, 0, len(d.New)+len(d.Gone)+len(d.Stuck))
+	entries = append(entries, d.New...)
+	entries = append(entries, d.Gone...)
+	return append(entries, d.Stuck...)
+}
+
+// outputDiffTable 以表格形式输出差异，每个桶一个表格
+// outputDiffTable outputs the difference in table format, one table per bucket
+func outputDiffTable(w io.Writer, diffs []cleaner.BucketDiff, opts Options) error {
+	if len(diffs) == 0 {
+		color.New(color.FgYellow).Fprintln(w, "两份报告中都没有过期文件\nNo stale files in either report")
+		return nil
+	}
+
+	cyan := color.New(color.FgCyan)
+	for _, d := range diffs {
+		cyan.Fprintf(w, "桶 | Bucket: %s  新增 New: %d  已消失 Gone: %d  仍存在 Stuck: %d\n", d.Bucket, len(d.New), len(d.Gone), len(d.Stuck))
+
+		table := tablewriter.NewWriter(w)
+		table.SetHeader([]string{"状态 | Status", "键 | Key", "修改时间 | Mod Time", "旧大小 | Old Size", "新大小 | New Size", "增长 | Growth"})
+		table.SetAutoWrapText(false)
+		table.SetAutoFormatHeaders(true)
+		table.SetHeaderColor(
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		)
+
+		for _, e := range diffEntries(d) {
+			var status string
+			var statusColor tablewriter.Colors
+			oldSize, newSize, growth := "-", "-", "-"
+			switch e.Status {
+			case cleaner.DiffNew:
+				status = "新增 | NEW"
+				statusColor = tablewriter.Colors{tablewriter.FgRedColor}
+				newSize = opts.formatSize(e.NewSize)
+			case cleaner.DiffGone:
+				status = "已消失 | GONE"
+				statusColor = tablewriter.Colors{tablewriter.FgGreenColor}
+				oldSize = opts.formatSize(e.OldSize)
+			default:
+				status = "仍存在 | STUCK"
+				statusColor = tablewriter.Colors{tablewriter.FgYellowColor}
+				oldSize = opts.formatSize(e.OldSize)
+				newSize = opts.formatSize(e.NewSize)
+				growth = "+" + opts.formatSize(e.Growth())
+				if e.Growth() < 0 {
+					growth = "-" + opts.formatSize(-e.Growth())
+				}
+			}
+
+			table.Rich([]string{status, truncateString(e.Key, 120), formatTime(e.ModTime, opts.location()), oldSize, newSize, growth}, []tablewriter.Colors{
+				statusColor,
+				tablewriter.Colors{tablewriter.FgWhiteColor},
+				tablewriter.Colors{tablewriter.FgWhiteColor},
+				tablewriter.Colors{tablewriter.FgHiCyanColor},
+				tablewriter.Colors{tablewriter.FgHiCyanColor},
+				statusColor,
+			})
+		}
+
+		table.Render()
+		fmt.Fprintln(w)
+	}
+	return nil
+}
+
+// outputDiffJSON 以JSON格式输出差异
+// outputDiffJSON outputs the difference in JSON format
+func outputDiffJSON(w io.Writer, diffs []cleaner.BucketDiff) error {
+	result := struct {
+		Buckets []cleaner.BucketDiff `json:"buckets"`
+	}{
+		Buckets: diffs,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化为JSON: %v\nFailed to serialize to JSON: %v", err, err)
+	}
+
+	fmt.Fprintln(w, string(jsonData))
+	return nil
+}
+
+// outputDiffCSV 以CSV格式输出差异
+// outputDiffCSV outputs the difference in CSV format
+func outputDiffCSV(w io.Writer, diffs []cleaner.BucketDiff, opts Options) error {
+	loc := opts.location()
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Status", "Bucket", "Key", "UploadID", "VersionID", "ModTime", "OldSize", "NewSize", "Growth"}); err != nil {
+		return fmt.Errorf("无法写入CSV表头: %v\nFailed to write CSV header: %v", err, err)
+	}
+
+	for _, d := range diffs {
+		for _, e := range diffEntries(d) {
+			record := []string{
+				e.Status,
+				e.Bucket,
+				e.Key,
+				e.UploadID,
+				e.VersionID,
+				inLocation(e.ModTime, loc).Format(time.RFC3339),
+				fmt.Sprintf("%d", e.OldSize),
+				fmt.Sprintf("%d", e.NewSize),
+				fmt.Sprintf("%d", e.Growth()),
+			}
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("无法写入CSV数据: %v\nFailed to write CSV data: %v", err, err)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestDiffTableSizeUnits(t *testing.T) {
+	diffs := []cleaner.BucketDiff{{
+		Bucket: "a",
+		Stuck:  []cleaner.DiffEntry{{Bucket: "a", Key: "x", Status: cleaner.DiffStuck, OldSize: 1000, NewSize: 3000}},
+	}}
+	tests := []struct {
+		units string
+		want  []string
+	}{
+		{SizeUnitsIEC, []string{"1000 B", "2.93 KiB", "+1.95 KiB"}},
+		{SizeUnitsSI, []string{"1.00 kB", "3.00 kB", "+2.00 kB"}},
+		{SizeUnitsBytes, []string{"1000 B", "3000 B", "+2000 B"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.units, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Diff(&buf, diffs, Options{SizeUnits: tt.units}); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("diff table missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestDiffTimeZone(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*60*60)
+	modTime := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	diffs := []cleaner.BucketDiff{{
+		Bucket: "a",
+		New:    []cleaner.DiffEntry{{Bucket: "a", Key: "x", Status: cleaner.DiffNew, ModTime: modTime, NewSize: 100}},
+	}}
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"table", "2025-06-01 20:00:00 +08:00"},
+		{"csv", "2025-06-01T20:00:00+08:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Diff(&buf, diffs, Options{Format: tt.format, Location: shanghai}); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("diff output missing %q:\n%s", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestDiffUnsupportedFormat(t *testing.T) {
+	for _, format := range []string{"html", "yaml"} {
+		err := Diff(&bytes.Buffer{}, nil, Options{Format: format})
+		if err == nil || !strings.Contains(err.Error(), "Format '"+format+"' is not supported for report diffs") {
+			t.Errorf("Diff(%q) error = %v, want the format to be rejected", format, err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// htmlTemplate HTML 报告模板，使用内联样式以便在邮件客户端中正常显示
+// htmlTemplate is the HTML report template, with inline styles so it displays correctly in mail clients
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"size":     FormatSizeUnits,
+	"cost":     FormatCost,
+	"status":   htmlFileStatus,
+	"lastPart": formatLastPart,
+	"time":     formatTime,
+	"age":      formatFileAge,
+	"rule":     formatRule,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>S4 Cleaner Report</title></head>
+<body style="font-family: -apple-system, 'Segoe UI', Helvetica, Arial, sans-serif; font-size: 14px; color: #222;">
+<h2>S4 Cleaner 报告 | Report ({{.Result.Target}})</h2>
+<p>{{time .Result.StartTime .Location}} · 耗时 Duration {{.Result.Duration.Round 1000000}}</p>
+{{- if .Result.FailedBuckets}}
+<p style="color: #c62828;">处理失败的桶 | Failed buckets: {{range $i, $b := .Result.FailedBuckets}}{{if $i}}, {{end}}{{$b}}{{end}}</p>
+{{- end}}
+{{- with .Result.Statistics}}
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse; margin-bottom: 16px;">
+<tr><td>总文件数 | Total files</td><td>{{.TotalFiles}}</td></tr>
+<tr><td>总容量 | Total size</td><td>{{size .TotalSize $.SizeUnits}}</td></tr>
+<tr><td>应删除文件数 | Files to delete</td><td>{{.FilesToDelete}}</td></tr>
+<tr><td>应删除容量 | Size to delete</td><td>{{size .SizeToDelete $.SizeUnits}}</td></tr>
+<tr><td>已删除文件数 | Files deleted</td><td>{{.FilesDeleted}}</td></tr>
+<tr><td>已删除容量 | Size deleted</td><td>{{size .SizeDeleted $.SizeUnits}}</td></tr>
+<tr><td>删除失败文件数 | Files failed</td><td>{{.FilesFailed}}</td></tr>
+{{- end}}
+{{- with .Result.Cost}}
+<tr><td>过期文件月成本 | Stale monthly cost</td><td>{{cost .StaleMonthly .Currency}}</td></tr>
+<tr><td>本次减少的月成本 | Monthly cost removed</td><td>{{cost .RemovedMonthly .Currency}}</td></tr>
+<tr><td>过期文件累计成本 | Stale cost so far</td><td>{{cost .StaleAccumulated .Currency}}</td></tr>
+{{- end}}
+{{- if lt (len .Files) (len .Result.Files)}}
+<tr><td>已显示文件数 | Files shown</td><td>{{len .Files}}</td></tr>
+{{- end}}
+<tr><td>请求重试次数 | Request retries</td><td>{{.Result.Retries}}</td></tr>
+</table>
+{{- if .Result.Groups}}
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse; margin-bottom: 16px;">
+<tr style="background: #e0f2f1;"><th>分组 | Group ({{.Result.GroupBy}})</th><th>文件数 | Files</th><th>容量 | Size</th><th>应删除 | To delete</th><th>应删除容量 | Size to delete</th><th>已删除 | Deleted</th><th>已删除容量 | Size deleted</th><th>删除失败 | Failed</th><th>删除失败容量 | Size failed</th></tr>
+{{- range .Result.Groups}}
+<tr><td>{{.Group}}</td><td>{{.TotalFiles}}</td><td>{{size .TotalSize $.SizeUnits}}</td><td>{{.FilesToDelete}}</td><td>{{size .SizeToDelete $.SizeUnits}}</td><td>{{.FilesDeleted}}</td><td>{{size .SizeDeleted $.SizeUnits}}</td><td>{{.FilesFailed}}</td><td>{{size .SizeFailed $.SizeUnits}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Files}}
+<table cellpadding="4" cellspacing="0" border="1" style="border-collapse: collapse;">
+<tr style="background: #e0f2f1;"><th>存储桶 | Bucket</th><th>键 | Key</th>{{if .ShowVersion}}<th>版本ID | Version ID</th>{{end}}<th>大小 | Size</th><th>修改时间 | Mod Time</th>{{if .ShowLastPart}}<th>最新分段 | Last Part</th>{{end}}<th>时长 | Age</th><th>状态 | Status</th>{{if .ShowRule}}<th>规则 | Rule</th>{{end}}</tr>
+{{- range .Files}}
+{{- $status := status .}}
+<tr><td>{{.Bucket}}</td><td>{{.Key}}</td>{{if $.ShowVersion}}<td>{{.VersionID}}</td>{{end}}<td>{{size .Size $.SizeUnits}}</td><td>{{time .ModTime $.Location}}</td>{{if $.ShowLastPart}}<td>{{lastPart . $.Location}}</td>{{end}}<td>{{age . $.Result.StartTime}}</td><td style="color: {{$status.Color}};">{{$status.Text}}</td>{{if $.ShowRule}}<td>{{rule .}}</td>{{end}}</tr>
+{{- end}}
+</table>
+{{- else}}
+<p>未找到临时文件 | No temporary files found</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// htmlStatusColors HTML 报告中每种文件状态的颜色
+// htmlStatusColors are the HTML report colors of each file status
+var htmlStatusColors = map[string]template.CSS{
+	statusSkipped:      "#8e24aa",
+	statusWillDelete:   "#e53935",
+	statusWontDelete:   "#f9a825",
+	statusDeleted:      "#2e7d32",
+	statusDeleteFailed: "#c62828",
+}
+
+// htmlStatus HTML 报告中文件状态的文字和颜色
+// htmlStatus is the text and color of a file status in the HTML report
+type htmlStatus struct {
+	Text  string
+	Color template.CSS
+}
+
+// htmlFileStatus 返回文件状态的文字和颜色
+// htmlFileStatus returns the text and color of the file status
+func htmlFileStatus(file cleaner.FileInfo) htmlStatus {
+	text, status := fileStatus(file)
+	return htmlStatus{Text: text, Color: htmlStatusColors[status]}
+}
+
+// outputHTML 以HTML格式输出结果
+// outputHTML outputs results in HTML format
+func outputHTML(w io.Writer, r *cleaner.Result, opts Options) error {
+	data := struct {
+		Result       *cleaner.Result
+		Files        []cleaner.FileInfo
+		Location     *time.Location
+		SizeUnits    string
+		ShowVersion  bool
+		ShowLastPart bool
+		ShowRule     bool
+	}{
+		Result:       r,
+		Files:        topFiles(r.Files, opts.Top),
+		Location:     opts.location(),
+		SizeUnits:    opts.SizeUnits,
+		ShowVersion:  r.Target == cleaner.TargetVersions,
+		ShowLastPart: r.AgeBasis == cleaner.AgeBasisLastPart,
+		ShowRule:     len(r.Rules) > 0,
+	}
+
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("无法生成HTML报告: %v\nFailed to render HTML report: %v", err, err)
+	}
+	return nil
+}
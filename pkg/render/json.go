@@ -0,0 +1,150 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// outputJSON 以JSON格式输出结果
+// outputJSON outputs results in JSON format
+func outputJSON(w io.Writer, r *cleaner.Result, opts Options) error {
+	loc := opts.location()
+	files := topFiles(r.Files, opts.Top)
+	jsonFiles := make([]jsonFile, 0, len(files))
+	for _, file := range files {
+		f := jsonFile{FileInfo: file, SizeFormatted: opts.formatSize(file.Size)}
+		f.ModTime = inLocation(file.ModTime, loc)
+		if file.LastPartTime != nil {
+			lastPart := file.LastPartTime.In(loc)
+			f.LastPartTime = &lastPart
+		}
+		if age, ok := fileAge(file, r.StartTime); ok {
+			seconds := int64(age / time.Second)
+			f.AgeSeconds = &seconds
+		}
+		jsonFiles = append(jsonFiles, f)
+	}
+
+	var groups []jsonGroup
+	for _, group := range r.Groups {
+		groups = append(groups, jsonGroup{Group: group.Group, jsonStatistics: newJSONStatistics(group.Statistics, opts)})
+	}
+
+	result := struct {
+		Files         []jsonFile     `json:"files"`
+		Total         int            `json:"total"`
+		Statistics    jsonStatistics `json:"statistics"`
+		Buckets       []string       `json:"buckets"`
+		FailedBuckets []string       `json:"failed_buckets,omitempty"`
+		Groups        []jsonGroup    `json:"groups,omitempty"`
+		Cost          cleaner.Cost   `json:"cost"`
+		Retries       int64          `json:"retries"`
+	}{
+		Files:         jsonFiles,
+		Total:         len(r.Files),
+		Statistics:    newJSONStatistics(r.Statistics, opts),
+		Buckets:       r.Buckets,
+		FailedBuckets: r.FailedBuckets,
+		Groups:        groups,
+		Cost:          r.Cost,
+		Retries:       r.Retries,
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化为JSON: %v\nFailed to serialize to JSON: %v", err, err)
+	}
+
+	fmt.Fprintln(w, string(jsonData))
+	return nil
+}
+
+// jsonFile JSON 输出中的文件，增加了年龄
+// jsonFile is a file in the JSON output, with its age added
+type jsonFile struct {
+	cleaner.FileInfo
+
+	// SizeFormatted 按 --sizeUnits 格式化的大小
+	// Size formatted in --sizeUnits
+	SizeFormatted string `json:"size_formatted"`
+
+	// AgeSeconds 文件在运行开始时的年龄（秒），文件没有时间时省略
+	// Age of the file in seconds when the run started, omitted when the file has no time
+	AgeSeconds *int64 `json:"age_seconds,omitempty"`
+}
+
+// jsonStatistics JSON 输出中的统计信息，增加了格式化的容量
+// jsonStatistics are the statistics in the JSON output, with the formatted sizes added
+type jsonStatistics struct {
+	cleaner.Statistics
+	TotalSizeFormatted    string `json:"total_size_formatted"`
+	SizeToDeleteFormatted string `json:"size_to_delete_formatted"`
+	SizeDeletedFormatted  string `json:"size_deleted_formatted"`
+	SizeFailedFormatted   string `json:"size_failed_formatted"`
+}
+
+// newJSONStatistics 按输出选项的容量单位格式化统计信息中的容量
+// newJSONStatistics formats the sizes of the statistics in the size units of the output options
+func newJSONStatistics(stats cleaner.Statistics, opts Options) jsonStatistics {
+	return jsonStatistics{
+		Statistics:            stats,
+		TotalSizeFormatted:    opts.formatSize(stats.TotalSize),
+		SizeToDeleteFormatted: opts.formatSize(stats.SizeToDelete),
+		SizeDeletedFormatted:  opts.formatSize(stats.SizeDeleted),
+		SizeFailedFormatted:   opts.formatSize(stats.SizeFailed),
+	}
+}
+
+// jsonGroup JSON 输出中的分组汇总
+// jsonGroup is a group summary in the JSON output
+type jsonGroup struct {
+	Group string `json:"group"`
+	jsonStatistics
+}
+
+// ReadJSONReport 读取 JSON 格式输出的报告，结果只包含文件列表、扫描成功的桶和处理失败的桶；
+// 没有记录扫描的桶的旧报告使用文件所在的桶；用 --top 截断的报告不完整，返回错误
+// ReadJSONReport reads a report written in JSON format, the result only contains the file list, the scanned buckets
+// and the failed buckets; older reports without the scanned buckets use the buckets of their files;
+// reports truncated with --top are incomplete and return an error
+func ReadJSONReport(r io.Reader) (*cleaner.Result, error) {
+	var report struct {
+		Files         []cleaner.FileInfo `json:"files"`
+		Total         *int               `json:"total"`
+		Buckets       *[]string          `json:"buckets"`
+		FailedBuckets []string           `json:"failed_buckets"`
+	}
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("无法解析JSON报告: %v\nFailed to parse JSON report: %v", err, err)
+	}
+	if report.Total == nil {
+		return nil, fmt.Errorf("不是 --fmt=json 输出的报告\nNot a report written with --fmt=json")
+	}
+	if len(report.Files) != *report.Total {
+		return nil, fmt.Errorf("报告只包含 %d 个文件中的 %d 个（使用了 --top），无法比较\nThe report only contains %d of %d files (written with --top) and cannot be compared", *report.Total, len(report.Files), len(report.Files), *report.Total)
+	}
+
+	buckets := []string{}
+	if report.Buckets != nil {
+		buckets = *report.Buckets
+	} else {
+		for _, file := range report.Files {
+			if !slices.Contains(buckets, file.Bucket) {
+				buckets = append(buckets, file.Bucket)
+			}
+		}
+	}
+	return &cleaner.Result{Files: report.Files, Buckets: buckets, FailedBuckets: report.FailedBuckets}, nil
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestReadJSONReportRoundTrip(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{
+		StartTime:     start,
+		Buckets:       []string{"a", "empty"},
+		FailedBuckets: []string{"broken"},
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", UploadID: "u1", Size: 100, ModTime: start.Add(-time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+		},
+	}
+	var buf bytes.Buffer
+	if err := outputJSON(&buf, result, Options{Location: time.UTC}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := ReadJSONReport(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(report.Buckets, result.Buckets) || !slices.Equal(report.FailedBuckets, result.FailedBuckets) {
+		t.Errorf("buckets = %v, failed = %v, want %v, %v", report.Buckets, report.FailedBuckets, result.Buckets, result.FailedBuckets)
+	}
+	if len(report.Files) != 1 || report.Files[0].UploadID != "u1" || !report.Files[0].ShouldDelete {
+		t.Errorf("files = %+v, want the upload u1", report.Files)
+	}
+}
+
+func TestReadJSONReport(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantBuckets []string
+		wantErr     string
+	}{
+		{
+			name:        "older report without buckets uses the buckets of its files",
+			in:          `{"files":[{"bucket":"a","key":"x"},{"bucket":"b","key":"y"},{"bucket":"a","key":"z"}],"total":3}`,
+			wantBuckets: []string{"a", "b"},
+		},
+		{
+			name:        "report with no files",
+			in:          `{"files":[],"total":0,"buckets":["a"]}`,
+			wantBuckets: []string{"a"},
+		},
+		{name: "truncated with --top", in: `{"files":[{"bucket":"a","key":"x"}],"total":2}`, wantErr: "--top"},
+		{name: "not a report", in: `{"files":[]}`, wantErr: "--fmt=json"},
+		{name: "invalid JSON", in: `{"files":`, wantErr: "JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := ReadJSONReport(strings.NewReader(tt.in))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadJSONReport() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(report.Buckets, tt.wantBuckets) {
+				t.Errorf("buckets = %v, want %v", report.Buckets, tt.wantBuckets)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// LifecycleRules 按格式输出 lifecycle 规则，支持 table（默认）, json, csv，其他格式返回错误
+// LifecycleRules writes lifecycle rules in the given format, supporting table (default), json and csv
+// and returning an error for any other format
+func LifecycleRules(w io.Writer, infos []cleaner.LifecycleInfo, format string) error {
+	switch strings.ToLower(format) {
+	case "", "table":
+		return outputLifecycleTable(w, infos)
+	case "json":
+		return outputLifecycleJSON(w, infos)
+	case "csv":
+		return outputLifecycleCSV(w, infos)
+	default:
+		return unsupportedFormat(format, "lifecycle 规则", "lifecycle rules", "table", "json", "csv")
+	}
+}
+
+// LifecycleChanges 输出每个桶的规则修改差异及其是否已应用
+// LifecycleChanges writes the rule diff of each bucket and whether it was applied
+func LifecycleChanges(w io.Writer, changes []cleaner.LifecycleChange) {
+	cyan := color.New(color.FgCyan)
+	red := color.New(color.FgRed)
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+
+	for _, change := range changes {
+		if change.Before == nil {
+			red.Fprintf(w, "处理桶 %s 时出错: %s\nError processing bucket %s: %s\n", change.Bucket, change.Error, change.Bucket, change.Error)
+			continue
+		}
+
+		cyan.Fprintf(w, "桶 | Bucket: %s\n", change.Bucket)
+		for _, line := range change.Before {
+			if slices.Contains(change.After, line) {
+				fmt.Fprintf(w, "  %s\n", line)
+			} else {
+				red.Fprintf(w, "- %s\n", line)
+			}
+		}
+		for _, line := range change.After {
+			if !slices.Contains(change.Before, line) {
+				green.Fprintf(w, "+ %s\n", line)
+			}
+		}
+		if !change.Changed {
+			yellow.Fprintln(w, "  无变化 | No changes")
+		}
+
+		switch {
+		case change.Error != "":
+			red.Fprintf(w, "处理桶 %s 时出错: %s\nError processing bucket %s: %s\n", change.Bucket, change.Error, change.Bucket, change.Error)
+		case change.Applied:
+			green.Fprintf(w, "已更新桶 %s 的 lifecycle 规则\nUpdated lifecycle rules of bucket %s\n", change.Bucket, change.Bucket)
+		}
+	}
+}
+
+// outputLifecycleTable 以表格形式输出 lifecycle 规则
+// outputLifecycleTable outputs lifecycle rules in table format
+func outputLifecycleTable(w io.Writer, infos []cleaner.LifecycleInfo) error {
+	if len(infos) == 0 {
+		color.New(color.FgYellow).Fprintln(w, "未找到 AbortIncompleteMultipartUpload 规则\nNo AbortIncompleteMultipartUpload rules found")
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"存储桶 | Bucket", "规则ID | Rule ID", "状态 | Status", "前缀 | Prefix", "天数 | Days"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+	)
+
+	for _, info := range infos {
+		statusColor := tablewriter.Colors{tablewriter.FgGreenColor}
+		if info.Status != string(types.ExpirationStatusEnabled) {
+			statusColor = tablewriter.Colors{tablewriter.FgYellowColor}
+		}
+		prefix := info.Prefix
+		if prefix == "" {
+			prefix = "(整个桶 | whole bucket)"
+		}
+		table.Rich([]string{info.Bucket, info.RuleID, info.Status, prefix, fmt.Sprintf("%d", info.Days)}, []tablewriter.Colors{
+			tablewriter.Colors{tablewriter.FgHiBlueColor},
+			tablewriter.Colors{tablewriter.FgWhiteColor},
+			statusColor,
+			tablewriter.Colors{tablewriter.FgWhiteColor},
+			tablewriter.Colors{tablewriter.FgHiCyanColor},
+		})
+	}
+
+	table.Render()
+	return nil
+}
+
+// outputLifecycleJSON 以JSON格式输出 lifecycle 规则
+// outputLifecycleJSON outputs lifecycle rules in JSON format
+func outputLifecycleJSON(w io.Writer, infos []cleaner.LifecycleInfo) error {
+	result := struct {
+		Rules []cleaner.LifecycleInfo `json:"rules"`
+		Total int                     `json:"total"`
+	}{
+		Rules: infos,
+		Total: len(infos),
+	}
+
+	jsonData, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("无法序列化为JSON: %v\nFailed to serialize to JSON: %v", err, err)
+	}
+
+	fmt.Fprintln(w, string(jsonData))
+	return nil
+}
+
+// outputLifecycleCSV 以CSV格式输出 lifecycle 规则
+// outputLifecycleCSV outputs lifecycle rules in CSV format
+func outputLifecycleCSV(w io.Writer, infos []cleaner.LifecycleInfo) error {
+	writer := csv.NewWriter(w)
+	defer writer.Flush()
+
+	if err := writer.Write([]string{"Bucket", "RuleID", "Status", "Prefix", "DaysAfterInitiation"}); err != nil {
+		return fmt.Errorf("无法写入CSV表头: %v\nFailed to write CSV header: %v", err, err)
+	}
+
+	for _, info := range infos {
+		if err := writer.Write([]string{info.Bucket, info.RuleID, info.Status, info.Prefix, fmt.Sprintf("%d", info.Days)}); err != nil {
+			return fmt.Errorf("无法写入CSV数据: %v\nFailed to write CSV data: %v", err, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLifecycleRulesFormats(t *testing.T) {
+	for _, format := range []string{"", "table", "JSON", "csv"} {
+		if err := LifecycleRules(io.Discard, nil, format); err != nil {
+			t.Errorf("LifecycleRules(%q) error = %v", format, err)
+		}
+	}
+	for _, format := range []string{"html", "yaml"} {
+		err := LifecycleRules(io.Discard, nil, format)
+		if err == nil || !strings.Contains(err.Error(), "Format '"+format+"' is not supported for lifecycle rules") {
+			t.Errorf("LifecycleRules(%q) error = %v, want the format to be rejected", format, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// 日志格式
+// Log formats
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// NewLogger 创建写入 w 的日志记录器，level 为 debug, info, warn, error
+// NewLogger creates a logger writing to w, level is one of debug, info, warn, error
+func NewLogger(level, format string, w io.Writer) (*slog.Logger, error) {
+	var logLevel slog.Level
+	switch strings.ToLower(level) {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "info":
+		logLevel = slog.LevelInfo
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	default:
+		return nil, fmt.Errorf("无效的日志级别 '%s'，有效选项为: debug, info, warn, error\nInvalid log level '%s', valid options are: debug, info, warn, error", level, level)
+	}
+
+	opts := &slog.HandlerOptions{Level: logLevel}
+	switch strings.ToLower(format) {
+	case LogFormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("无效的日志格式 '%s'，有效选项为: text, json\nInvalid log format '%s', valid options are: text, json", format, format)
+	}
+}
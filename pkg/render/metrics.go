@@ -0,0 +1,175 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// pushgatewayJob 推送到 Pushgateway 时使用的 job 名称
+// pushgatewayJob is the job name used when pushing to the Pushgateway
+const pushgatewayJob = "s4-cleaner"
+
+// bucketStatistics 按桶统计信息
+// bucketStatistics returns statistics of the result grouped by bucket
+func bucketStatistics(r *cleaner.Result) map[string]cleaner.Statistics {
+	filesByBucket := make(map[string][]cleaner.FileInfo, len(r.Buckets))
+	for _, file := range r.Files {
+		filesByBucket[file.Bucket] = append(filesByBucket[file.Bucket], file)
+	}
+
+	stats := make(map[string]cleaner.Statistics, len(r.Buckets))
+	for _, bucket := range r.Buckets {
+		stats[bucket] = cleaner.ComputeStatistics(filesByBucket[bucket])
+	}
+	return stats
+}
+
+// WriteMetrics 以 Prometheus 文本格式写入结果的指标，每个指标都带有清理目标标签，
+// 指标名沿用分段上传的命名，以兼容已有的面板和告警
+// WriteMetrics writes the metrics of the result in Prometheus text exposition format. Every metric
+// carries the cleaning target as a label, the names keep their multipart upload wording so existing
+// dashboards and alerts still work
+func WriteMetrics(w io.Writer, r *cleaner.Result) error {
+	stats := bucketStatistics(r)
+	target := escapeLabelValue(metricsTarget(r))
+
+	var buf bytes.Buffer
+	writeBucketGauge := func(name, help string, value func(cleaner.Statistics) int64) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, bucket := range r.Buckets {
+			fmt.Fprintf(&buf, "%s{target=\"%s\",bucket=\"%s\"} %d\n", name, target, escapeLabelValue(bucket), value(stats[bucket]))
+		}
+	}
+	writeGauge := func(name, help, value string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n%s{target=\"%s\"} %s\n", name, help, name, name, target, value)
+	}
+
+	writeBucketGauge("s4_cleaner_stale_uploads", "Number of stale uploads, objects or versions (see the target label) older than the cutoff.",
+		func(s cleaner.Statistics) int64 { return int64(s.FilesToDelete) })
+	writeBucketGauge("s4_cleaner_stale_bytes", "Bytes held by stale uploads, objects or versions (see the target label) older than the cutoff.",
+		func(s cleaner.Statistics) int64 { return s.SizeToDelete })
+	writeBucketGauge("s4_cleaner_aborted_uploads", "Number of uploads aborted, or objects or versions deleted, by the last run.",
+		func(s cleaner.Statistics) int64 { return int64(s.FilesDeleted) })
+	writeBucketGauge("s4_cleaner_aborted_bytes", "Bytes freed by uploads aborted, or objects or versions deleted, in the last run.",
+		func(s cleaner.Statistics) int64 { return s.SizeDeleted })
+	writeBucketGauge("s4_cleaner_abort_failures", "Number of uploads, objects or versions that failed to be removed in the last run.",
+		func(s cleaner.Statistics) int64 { return int64(s.FilesFailed) })
+	writeGauge("s4_cleaner_bucket_failures", "Number of buckets that could not be scanned in the last run.",
+		fmt.Sprintf("%d", len(r.FailedBuckets)))
+	writeGauge("s4_cleaner_scan_duration_seconds", "Duration of the last scan in seconds.",
+		fmt.Sprintf("%g", r.Duration().Seconds()))
+	writeGauge("s4_cleaner_last_run_timestamp_seconds", "Unix timestamp of the start of the last run.",
+		fmt.Sprintf("%d", r.StartTime.Unix()))
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// metricsTarget 返回指标使用的清理目标，未指定时为分段上传
+// metricsTarget returns the cleaning target used in metrics, multipart uploads when unset
+func metricsTarget(r *cleaner.Result) string {
+	if r.Target == "" {
+		return cleaner.TargetUploads
+	}
+	return r.Target
+}
+
+// escapeLabelValue 转义 Prometheus 标签值
+// escapeLabelValue escapes a Prometheus label value
+func escapeLabelValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// ExportMetrics 写入指标文件并推送到 Pushgateway，路径或地址为空时跳过
+// ExportMetrics writes the metrics file and pushes to the Pushgateway, skipping whichever is empty
+func ExportMetrics(r *cleaner.Result, metricsFile, pushgateway string) error {
+	if metricsFile == "" && pushgateway == "" {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, r); err != nil {
+		return fmt.Errorf("无法生成指标: %v\nFailed to render metrics: %v", err, err)
+	}
+
+	if metricsFile != "" {
+		if err := writeMetricsFile(metricsFile, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if pushgateway != "" {
+		if err := pushMetrics(pushgateway, metricsTarget(r), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMetricsFile 原子地写入指标文件，避免 node_exporter 读到不完整的内容
+// writeMetricsFile writes the metrics file atomically so node_exporter never reads a partial file
+func writeMetricsFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("无法创建指标文件: %v\nFailed to create metrics file: %v", err, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("无法写入指标文件: %v\nFailed to write metrics file: %v", err, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("无法写入指标文件: %v\nFailed to write metrics file: %v", err, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("无法写入指标文件: %v\nFailed to write metrics file: %v", err, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("无法写入指标文件: %v\nFailed to write metrics file: %v", err, err)
+	}
+	return nil
+}
+
+// pushMetrics 推送指标到 Pushgateway，按清理目标分组，只替换同一 job 和目标下的旧指标
+// pushMetrics pushes metrics to the Pushgateway grouped by cleaning target, replacing only older metrics of the same job and target
+func pushMetrics(gateway, target string, data []byte) error {
+	endpoint := strings.TrimRight(gateway, "/") + "/metrics/job/" + pushgatewayJob + "/target/" + url.PathEscape(target)
+
+	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("无法推送指标到 %s: %v\nFailed to push metrics to %s: %v", gateway, err, gateway, err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("无法推送指标到 %s: %v\nFailed to push metrics to %s: %v", gateway, err, gateway, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		body = bytes.TrimSpace(body)
+		return fmt.Errorf("无法推送指标到 %s: %s %s\nFailed to push metrics to %s: %s %s", gateway, resp.Status, body, gateway, resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestWriteMetricsTargetLabel(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"", "uploads"},
+		{cleaner.TargetObjects, "objects"},
+		{cleaner.TargetVersions, "versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			result := &cleaner.Result{
+				StartTime: start,
+				Target:    tt.target,
+				Buckets:   []string{"a"},
+				Files:     []cleaner.FileInfo{{Bucket: "a", Key: "x", Size: 100, ShouldDelete: true}},
+			}
+			var buf bytes.Buffer
+			if err := WriteMetrics(&buf, result); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			for _, line := range []string{
+				`s4_cleaner_stale_uploads{target="` + tt.want + `",bucket="a"} 1`,
+				`s4_cleaner_stale_bytes{target="` + tt.want + `",bucket="a"} 100`,
+				`s4_cleaner_bucket_failures{target="` + tt.want + `"} 0`,
+			} {
+				if !strings.Contains(out, line+"\n") {
+					t.Errorf("metrics missing %q:\n%s", line, out)
+				}
+			}
+		})
+	}
+}
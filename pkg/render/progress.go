@@ -0,0 +1,176 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/mattn/go-isatty"
+)
+
+// 进度输出方式
+// Progress modes
+const (
+	ProgressAuto = "auto"
+	ProgressLine = "line"
+	ProgressJSON = "json"
+	ProgressNone = "none"
+)
+
+// 进度刷新间隔
+// Progress refresh intervals
+const (
+	progressLineInterval = 200 * time.Millisecond
+	progressJSONInterval = 5 * time.Second
+)
+
+// Progress 定期输出清理器的进度事件，每次运行在第一个事件时开始计时，在完成事件时停止
+// Progress writes the progress events of the cleaner periodically, ticking from the first event of a run until its done event
+type Progress struct {
+	mode      string
+	sizeUnits string
+	out       io.Writer
+
+	mu   sync.Mutex
+	last cleaner.ProgressEvent
+	stop chan struct{}
+}
+
+// NewProgress 创建进度输出，auto 在 out 是终端时输出进度行，否则不输出；进度行中的容量按 sizeUnits 格式化；不输出时返回 nil
+// NewProgress creates a progress writer, auto shows a progress line when out is a terminal and nothing otherwise;
+// sizes in the progress line are formatted in sizeUnits; returns nil when nothing is written
+func NewProgress(mode, sizeUnits string, out *os.File) (*Progress, error) {
+	switch mode {
+	case ProgressAuto:
+		if !isatty.IsTerminal(out.Fd()) && !isatty.IsCygwinTerminal(out.Fd()) {
+			return nil, nil
+		}
+		mode = ProgressLine
+	case ProgressLine, ProgressJSON:
+	case ProgressNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("无效的进度输出方式 '%s'，有效选项为: auto, line, json, none\nInvalid progress mode '%s', valid options are: auto, line, json, none", mode, mode)
+	}
+
+	return &Progress{mode: mode, sizeUnits: sizeUnits, out: out}, nil
+}
+
+// Hook 返回可用作 Options.OnProgress 的回调，p 为 nil 时返回 nil
+// Hook returns a callback usable as Options.OnProgress, or nil if p is nil
+func (p *Progress) Hook() func(cleaner.ProgressEvent) {
+	if p == nil {
+		return nil
+	}
+	return p.update
+}
+
+// update 记录最新进度，第一个事件时开始定期输出，完成事件时停止并输出最终状态
+// update records the latest progress, starts the periodic output on the first event,
+// and stops it and writes the final state on the done event
+func (p *Progress) update(event cleaner.ProgressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last = event
+	if event.Event == cleaner.ProgressEventDone {
+		if p.stop != nil {
+			close(p.stop)
+			p.stop = nil
+		}
+		p.render(event)
+		return
+	}
+
+	if p.stop == nil {
+		p.stop = make(chan struct{})
+		go p.loop(p.stop)
+	}
+}
+
+// loop 定期输出最新进度，直到停止
+// loop writes the latest progress periodically until stopped
+func (p *Progress) loop(stop chan struct{}) {
+	interval := progressLineInterval
+	if p.mode == ProgressJSON {
+		interval = progressJSONInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	start := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+			p.mu.Lock()
+			select {
+			case <-stop:
+			default:
+				event := p.last
+				event.ElapsedSeconds = max(event.ElapsedSeconds, time.Since(start).Seconds())
+				p.render(event)
+			}
+			p.mu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// render 输出一次进度；进度行在完成时清除，避免和报告混在一起
+// render writes the progress once; the progress line is cleared when done so it does not mix with the report
+func (p *Progress) render(event cleaner.ProgressEvent) {
+	if p.mode == ProgressJSON {
+		data, _ := json.Marshal(event)
+		fmt.Fprintln(p.out, string(data))
+		return
+	}
+
+	if event.Event == cleaner.ProgressEventDone {
+		fmt.Fprint(p.out, "\r\033[K")
+		return
+	}
+
+	bucketIndex := min(event.BucketsDone+1, event.BucketsTotal)
+	fmt.Fprintf(p.out, "\r\033[K桶 Bucket %s (%d/%d) | 页 Pages %d | 已扫描 Scanned %d | 待删除 To delete %d (%s) | 已删除 Deleted %d | 失败 Failed %d | %s",
+		event.Bucket, bucketIndex, event.BucketsTotal, event.Pages, event.FilesScanned,
+		event.FilesToDelete, FormatSizeUnits(event.SizeToDelete, p.sizeUnits), event.FilesDeleted, event.FilesFailed,
+		time.Duration(event.ElapsedSeconds*float64(time.Second)).Truncate(time.Second))
+}
+
+// progressWriter 写入前清除进度行的 Writer
+// progressWriter is a Writer that clears the progress line before writing
+type progressWriter struct {
+	p *Progress
+	w io.Writer
+}
+
+// Writer 返回与进度输出共用终端的 Writer，写入前先清除进度行，下次刷新时重新输出；p 为 nil 时直接返回 w
+// Writer returns a Writer sharing the terminal with the progress output, which clears the progress line before
+// each write so the next refresh draws it again; returns w itself if p is nil
+func (p *Progress) Writer(w io.Writer) io.Writer {
+	if p == nil || p.mode != ProgressLine {
+		return w
+	}
+	return &progressWriter{p: p, w: w}
+}
+
+// Write 清除进度行后写入
+// Write clears the progress line and then writes
+func (pw *progressWriter) Write(data []byte) (int, error) {
+	pw.p.mu.Lock()
+	defer pw.p.mu.Unlock()
+	fmt.Fprint(pw.p.out, "\r\033[K")
+	return pw.w.Write(data)
+}
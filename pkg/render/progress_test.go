@@ -0,0 +1,25 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestProgressLineSizeUnits(t *testing.T) {
+	var buf bytes.Buffer
+	p := &Progress{mode: ProgressLine, sizeUnits: SizeUnitsSI, out: &buf}
+	p.render(cleaner.ProgressEvent{Bucket: "a", BucketsTotal: 1, FilesToDelete: 2, SizeToDelete: 2000})
+	if !strings.Contains(buf.String(), "(2.00 kB)") {
+		t.Errorf("progress line = %q, want the size in SI units", buf.String())
+	}
+}
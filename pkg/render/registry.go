@@ -0,0 +1,92 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// Renderer 以某种格式输出一次运行的结果
+// Renderer writes the result of one run in a particular format
+type Renderer interface {
+	Render(w io.Writer, r *cleaner.Result, opts Options) error
+}
+
+// RendererFunc 将普通函数适配为 Renderer
+// RendererFunc adapts an ordinary function to a Renderer
+type RendererFunc func(w io.Writer, r *cleaner.Result, opts Options) error
+
+// Render 调用 f(w, r, opts)
+// Render calls f(w, r, opts)
+func (f RendererFunc) Render(w io.Writer, r *cleaner.Result, opts Options) error {
+	return f(w, r, opts)
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{}
+)
+
+// Register 以格式名注册输出器，名称不区分大小写，重复注册会覆盖之前的输出器
+// Register registers a renderer under a format name, names are case-insensitive
+// and registering an existing name replaces the previous renderer
+func Register(format string, renderer Renderer) {
+	if renderer == nil {
+		panic("render: Register renderer is nil")
+	}
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	renderers[strings.ToLower(format)] = renderer
+}
+
+// Lookup 查找格式对应的输出器
+// Lookup finds the renderer of a format
+func Lookup(format string) (Renderer, error) {
+	renderersMu.RLock()
+	renderer, ok := renderers[strings.ToLower(format)]
+	renderersMu.RUnlock()
+	if !ok {
+		valid := strings.Join(Formats(), ", ")
+		return nil, fmt.Errorf("无效的格式 '%s'，有效选项为: %s\nInvalid format '%s', valid options are: %s", format, valid, format, valid)
+	}
+	return renderer, nil
+}
+
+// unsupportedFormat 返回某种输出不支持该格式的错误，zh 和 en 为输出的中英文名称，valid 为支持的格式
+// unsupportedFormat returns the error for a format an output does not support, zh and en naming the output
+// in Chinese and English and valid listing the supported formats
+func unsupportedFormat(format, zh, en string, valid ...string) error {
+	list := strings.Join(valid, ", ")
+	return fmt.Errorf("%s不支持格式 '%s'，有效选项为: %s\nFormat '%s' is not supported for %s, valid options are: %s", zh, format, list, format, en, list)
+}
+
+// Formats 返回所有已注册的格式名，按字母排序
+// Formats returns the names of all registered formats in alphabetical order
+func Formats() []string {
+	renderersMu.RLock()
+	defer renderersMu.RUnlock()
+	formats := make([]string, 0, len(renderers))
+	for format := range renderers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func init() {
+	Register("table", RendererFunc(outputTable))
+	Register("json", RendererFunc(outputJSON))
+	Register("csv", RendererFunc(outputCSV))
+	Register("html", RendererFunc(outputHTML))
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestRegistry(t *testing.T) {
+	for _, format := range []string{"table", "json", "csv", "html"} {
+		if _, err := Lookup(format); err != nil {
+			t.Errorf("Lookup(%q) error = %v", format, err)
+		}
+	}
+
+	Register("Test-Count", RendererFunc(func(w io.Writer, r *cleaner.Result, opts Options) error {
+		_, err := fmt.Fprintf(w, "%d files", len(r.Files))
+		return err
+	}))
+	if formats := Formats(); !slices.IsSorted(formats) || !slices.Contains(formats, "test-count") {
+		t.Errorf("Formats() = %v, want a sorted list with test-count", formats)
+	}
+
+	// 格式名不区分大小写 | Format names are case-insensitive
+	var buf bytes.Buffer
+	if err := Result(&buf, &cleaner.Result{Files: make([]cleaner.FileInfo, 2)}, Options{Format: "TEST-count"}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "2 files" {
+		t.Errorf("output = %q, want the registered renderer's output", buf.String())
+	}
+
+	_, err := Lookup("yaml")
+	if err == nil || !strings.Contains(err.Error(), "Invalid format 'yaml'") || !strings.Contains(err.Error(), "test-count") {
+		t.Errorf("Lookup(yaml) error = %v, want the list of valid formats", err)
+	}
+}
+
+func TestRegisterNil(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register(nil) did not panic")
+		}
+	}()
+	Register("nil", nil)
+}
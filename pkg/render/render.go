@@ -0,0 +1,192 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// Options 输出选项
+// Options configures the output
+type Options struct {
+	// Format 输出格式：table, json, csv 或通过 Register 注册的其他格式
+	// Output format: table, json, csv or any other format added with Register
+	Format string
+
+	// Top 只输出前N条记录，统计信息仍包含全部文件，0 表示全部输出
+	// Only output the first N rows while statistics still include all files, 0 means all
+	Top int
+
+	// Location 输出时间使用的时区，nil 表示本地时区
+	// Time zone of the output times, nil means the local time zone
+	Location *time.Location
+
+	// SizeUnits 容量单位：iec, si, bytes，为空表示 iec
+	// Size units: iec, si, bytes, empty means iec
+	SizeUnits string
+}
+
+// location 返回输出时间使用的时区
+// location returns the time zone of the output times
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.Local
+	}
+	return o.Location
+}
+
+// Result 按格式输出一次运行的结果
+// Result writes the result of one run in the configured format
+func Result(w io.Writer, r *cleaner.Result, opts Options) error {
+	renderer, err := Lookup(opts.Format)
+	if err != nil {
+		return err
+	}
+	return renderer.Render(w, r, opts)
+}
+
+// topFiles 返回需要输出的前N个文件
+// topFiles returns the first N files to output
+func topFiles(files []cleaner.FileInfo, top int) []cleaner.FileInfo {
+	if top > 0 && len(files) > top {
+		return files[:top]
+	}
+	return files
+}
+
+// timeLayout 表格和 HTML 报告中的时间格式，带时区偏移
+// timeLayout is the time format of the table and HTML reports, with the zone offset
+const timeLayout = "2006-01-02 15:04:05 -07:00"
+
+// inLocation 将时间转换到时区，零值保持不变
+// inLocation converts the time to the time zone, leaving the zero value unchanged
+func inLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
+
+// formatTime 按时区格式化时间，零值显示 -
+// formatTime formats the time in the time zone, showing - for the zero value
+func formatTime(t time.Time, loc *time.Location) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.In(loc).Format(timeLayout)
+}
+
+// fileAge 返回文件在 now 时的年龄（分段上传按 --ageBasis 计算）；文件没有时间时返回 false
+// fileAge returns the age of the file at now (by --ageBasis for multipart uploads); returns false when the file has no time
+func fileAge(file cleaner.FileInfo, now time.Time) (time.Duration, bool) {
+	t := file.AgeTime()
+	if t.IsZero() {
+		return 0, false
+	}
+	return max(now.Sub(t), 0), true
+}
+
+// FormatAge 格式化时长，如 8d 3h、5h 20m 或 12m
+// FormatAge formats a duration, e.g. 8d 3h, 5h 20m or 12m
+func FormatAge(d time.Duration) string {
+	const day = 24 * time.Hour
+	days := d / day
+	hours := d % day / time.Hour
+	minutes := d % time.Hour / time.Minute
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
+
+// formatFileAge 格式化文件在 now 时的年龄，文件没有时间时显示 -
+// formatFileAge formats the age of the file at now, showing - when the file has no time
+func formatFileAge(file cleaner.FileInfo, now time.Time) string {
+	age, ok := fileAge(file, now)
+	if !ok {
+		return "-"
+	}
+	return FormatAge(age)
+}
+
+// 容量单位
+// Size units
+const (
+	// SizeUnitsIEC 以 1024 为进制，单位为 KiB, MiB, GiB, TiB
+	// SizeUnitsIEC uses powers of 1024 with the units KiB, MiB, GiB, TiB
+	SizeUnitsIEC = "iec"
+
+	// SizeUnitsSI 以 1000 为进制，单位为 kB, MB, GB, TB
+	// SizeUnitsSI uses powers of 1000 with the units kB, MB, GB, TB
+	SizeUnitsSI = "si"
+
+	// SizeUnitsBytes 始终以字节显示
+	// SizeUnitsBytes always shows bytes
+	SizeUnitsBytes = "bytes"
+)
+
+// sizeScales 每种容量单位从大到小的进制和单位名称
+// sizeScales are the scales and unit names of each size unit system, from largest to smallest
+var sizeScales = map[string][]struct {
+	factor int64
+	unit   string
+}{
+	SizeUnitsIEC:   {{1 << 40, "TiB"}, {1 << 30, "GiB"}, {1 << 20, "MiB"}, {1 << 10, "KiB"}},
+	SizeUnitsSI:    {{1e12, "TB"}, {1e9, "GB"}, {1e6, "MB"}, {1e3, "kB"}},
+	SizeUnitsBytes: nil,
+}
+
+// CheckSizeUnits 检查容量单位是否有效
+// CheckSizeUnits checks whether the size units are valid
+func CheckSizeUnits(units string) error {
+	if _, ok := sizeScales[units]; !ok {
+		return fmt.Errorf("无效的容量单位 '%s'，有效选项为: iec, si, bytes\nInvalid size units '%s', valid options are: iec, si, bytes", units, units)
+	}
+	return nil
+}
+
+// FormatSize 按 IEC 单位格式化文件大小
+// FormatSize formats file size in IEC units
+func FormatSize(size int64) string {
+	return FormatSizeUnits(size, SizeUnitsIEC)
+}
+
+// FormatSizeUnits 按容量单位格式化文件大小，units 为空时使用 IEC 单位
+// FormatSizeUnits formats file size in the size units, using IEC units when units is empty
+func FormatSizeUnits(size int64, units string) string {
+	if units == "" {
+		units = SizeUnitsIEC
+	}
+	for _, scale := range sizeScales[units] {
+		if size >= scale.factor {
+			return fmt.Sprintf("%.2f %s", float64(size)/float64(scale.factor), scale.unit)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// formatSize 按输出选项的容量单位格式化文件大小
+// formatSize formats file size in the size units of the output options
+func (o Options) formatSize(size int64) string {
+	return FormatSizeUnits(size, o.SizeUnits)
+}
+
+// FormatCost 格式化成本金额
+// FormatCost formats a cost amount
+func FormatCost(amount float64, currency string) string {
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestTopTruncatesOutput(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{StartTime: start, Target: cleaner.TargetUploads}
+	for _, key := range []string{"big", "medium", "small"} {
+		result.Files = append(result.Files, cleaner.FileInfo{Bucket: "a", Key: key, UploadID: key, ModTime: start, ShouldDelete: true})
+	}
+	result.Statistics = cleaner.ComputeStatistics(result.Files)
+
+	for _, tt := range []struct {
+		top  int
+		want []string
+	}{
+		{0, []string{"big", "medium", "small"}},
+		{2, []string{"big", "medium"}},
+		{5, []string{"big", "medium", "small"}},
+	} {
+		opts := Options{Top: tt.top, Location: time.UTC}
+
+		var buf bytes.Buffer
+		if err := outputCSV(&buf, result, opts); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, row := range rows[1:] {
+			keys = append(keys, row[1])
+		}
+		if strings.Join(keys, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("--top %d CSV rows = %v, want %v", tt.top, keys, tt.want)
+		}
+
+		// JSON 只截断明细，总数仍包含全部文件 | JSON only truncates the rows, the total still counts every file
+		buf.Reset()
+		if err := outputJSON(&buf, result, opts); err != nil {
+			t.Fatal(err)
+		}
+		var report struct {
+			Files []cleaner.FileInfo `json:"files"`
+			Total int                `json:"total"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Files) != len(tt.want) || report.Total != 3 {
+			t.Errorf("--top %d JSON has %d files of %d, want %d of 3", tt.top, len(report.Files), report.Total, len(tt.want))
+		}
+	}
+}
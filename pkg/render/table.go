@@ -0,0 +1,351 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+)
+
+// outputTable 以表格形式输出结果
+// outputTable outputs results in table format
+func outputTable(w io.Writer, r *cleaner.Result, opts Options) error {
+	files := topFiles(r.Files, opts.Top)
+	loc := opts.location()
+	showVersion := r.Target == cleaner.TargetVersions
+	showLastPart := r.AgeBasis == cleaner.AgeBasisLastPart
+	showRule := len(r.Rules) > 0
+
+	// 表头和每一行按相同的顺序追加可选列，保证列对齐
+	// The header and every row append the optional columns in the same order so the columns line up
+	header := []string{"存储桶 | Bucket", "键 | Key"}
+	if showVersion {
+		header = append(header, "版本ID | Version ID")
+	}
+	header = append(header, "大小 | Size", "修改时间 | Mod Time")
+	if showLastPart {
+		header = append(header, "最新分段 | Last Part")
+	}
+	header = append(header, "时长 | Age", "状态 | Status")
+	if showRule {
+		header = append(header, "规则 | Rule")
+	}
+	headerColors := make([]tablewriter.Colors, len(header))
+	for i := range headerColors {
+		headerColors[i] = tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor}
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(header)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderColor(headerColors...)
+
+	// 设置列宽，增加 Key 列的宽度
+	// Set column width, increase Key column width
+	table.SetColWidth(120)
+
+	for _, file := range files {
+		// 截断过长的键
+		// Truncate long keys
+		key := file.Key
+		if utf8.RuneCountInString(key)*3 > 120 {
+			key = truncateString(key, 120)
+		}
+
+		// 格式化时间
+		// Format time
+		timeStr := formatTime(file.ModTime, loc)
+
+		// 格式化大小
+		// Format size
+		sizeStr := opts.formatSize(file.Size)
+
+		// 格式化状态，使用表情符号和文字
+		// Format status with emoji and text
+		statusStr, status := fileStatus(file)
+		statusColor := statusColors[status]
+
+		// 根据是否应删除设置时间列的颜色
+		// Set time column color based on should delete
+		var timeColor tablewriter.Colors
+		if file.ShouldDelete {
+			timeColor = tablewriter.Colors{tablewriter.FgHiRedColor}
+		} else {
+			timeColor = tablewriter.Colors{tablewriter.FgYellowColor}
+		}
+
+		row := []string{file.Bucket, key}
+		colors := []tablewriter.Colors{
+			tablewriter.Colors{tablewriter.FgHiBlueColor},
+			tablewriter.Colors{tablewriter.FgWhiteColor},
+		}
+		if showVersion {
+			versionStr := file.VersionID
+			if file.Type == cleaner.FileTypeDeleteMarker {
+				versionStr += " (delete marker)"
+			}
+			row = append(row, versionStr)
+			colors = append(colors, tablewriter.Colors{tablewriter.FgWhiteColor})
+		}
+		row = append(row, sizeStr, timeStr)
+		colors = append(colors, tablewriter.Colors{tablewriter.FgHiCyanColor}, timeColor)
+		if showLastPart {
+			row = append(row, formatLastPart(file, loc))
+			colors = append(colors, timeColor)
+		}
+		row = append(row, formatFileAge(file, r.StartTime), statusStr)
+		colors = append(colors, timeColor, statusColor)
+		if showRule {
+			row = append(row, formatRule(file))
+			colors = append(colors, tablewriter.Colors{tablewriter.FgWhiteColor})
+		}
+
+		table.Rich(row, colors)
+	}
+
+	if len(files) == 0 {
+		color.New(color.FgYellow).Fprintln(w, "未找到临时文件\nNo temporary files found")
+	} else {
+		table.Render()
+
+		stats := r.Statistics
+
+		// 输出统计信息
+		// Output statistics
+		fmt.Fprintln(w)
+
+		// 创建统计信息表格
+		// Create statistics table
+		statTable := tablewriter.NewWriter(w)
+		statTable.SetHeader([]string{"统计信息 | Statistics", "值 | Value"})
+		statTable.SetAutoWrapText(false)
+		statTable.SetAutoFormatHeaders(true)
+		statTable.SetHeaderColor(
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+			tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		)
+		statTable.SetColumnColor(
+			tablewriter.Colors{tablewriter.FgHiWhiteColor},
+			tablewriter.Colors{tablewriter.FgHiWhiteColor},
+		)
+
+		// 添加统计数据行
+		// Add statistics data rows
+		statTable.Append([]string{"总文件数 | Total files", fmt.Sprintf("%d", stats.TotalFiles)})
+		statTable.Append([]string{"总容量 | Total size", opts.formatSize(stats.TotalSize)})
+		statTable.Append([]string{"应删除文件数 | Files to delete", fmt.Sprintf("%d", stats.FilesToDelete)})
+		statTable.Append([]string{"应删除容量 | Size to delete", opts.formatSize(stats.SizeToDelete)})
+		statTable.Append([]string{"已删除文件数 | Files deleted", fmt.Sprintf("%d", stats.FilesDeleted)})
+		statTable.Append([]string{"已删除容量 | Size deleted", opts.formatSize(stats.SizeDeleted)})
+		statTable.Append([]string{"过期文件月成本 | Stale monthly cost", FormatCost(r.Cost.StaleMonthly, r.Cost.Currency)})
+		statTable.Append([]string{"本次减少的月成本 | Monthly cost removed", FormatCost(r.Cost.RemovedMonthly, r.Cost.Currency)})
+		statTable.Append([]string{"过期文件累计成本 | Stale cost so far", FormatCost(r.Cost.StaleAccumulated, r.Cost.Currency)})
+		if len(files) < len(r.Files) {
+			statTable.Append([]string{"已显示文件数 | Files shown", fmt.Sprintf("%d", len(files))})
+		}
+		statTable.Append([]string{"请求重试次数 | Request retries", fmt.Sprintf("%d", r.Retries)})
+
+		// 渲染统计表格
+		// Render statistics table
+		statTable.Render()
+
+		// 输出分组汇总
+		// Output group summary
+		if r.Groups != nil {
+			fmt.Fprintln(w)
+			outputGroupTable(w, r, opts)
+		}
+	}
+	return nil
+}
+
+// 文件状态
+// File statuses
+const (
+	statusSkipped      = "skipped"
+	statusWillDelete   = "will-delete"
+	statusWontDelete   = "wont-delete"
+	statusDeleted      = "deleted"
+	statusDeleteFailed = "delete-failed"
+)
+
+// statusColors 表格中每种文件状态的颜色
+// statusColors are the table colors of each file status
+var statusColors = map[string]tablewriter.Colors{
+	statusSkipped:      {tablewriter.FgMagentaColor},
+	statusWillDelete:   {tablewriter.FgHiRedColor},
+	statusWontDelete:   {tablewriter.FgYellowColor},
+	statusDeleted:      {tablewriter.FgGreenColor},
+	statusDeleteFailed: {tablewriter.FgRedColor},
+}
+
+// fileStatus 返回文件状态的显示文字和状态
+// fileStatus returns the display text and the status of a file
+func fileStatus(file cleaner.FileInfo) (string, string) {
+	switch {
+	case file.Skipped:
+		return "⏭️ Skipped (budget)", statusSkipped // 超出删除预算而跳过 | Skipped because the delete budget was exceeded
+	case file.DeleteSuccess == nil && file.ShouldDelete:
+		// 未执行删除操作时，显示是否会被命中删除
+		// When deletion is not executed, show if it would be targeted for deletion
+		return "🎯 Will delete", statusWillDelete // 会被命中删除 | Would be targeted for deletion
+	case file.DeleteSuccess == nil:
+		return "🔍 Won't delete", statusWontDelete // 不会被命中删除 | Would not be targeted for deletion
+	case *file.DeleteSuccess:
+		return "✅ Deleted", statusDeleted // 删除成功 | Deletion Success
+	default:
+		return "❌ Delete failed", statusDeleteFailed // 删除失败 | Deletion Failed
+	}
+}
+
+// outputGroupTable 以表格形式输出分组汇总
+// outputGroupTable outputs the group summary in table format
+func outputGroupTable(w io.Writer, r *cleaner.Result, opts Options) {
+	groupTable := tablewriter.NewWriter(w)
+	groupTable.SetHeader([]string{
+		"分组 | Group (" + r.GroupBy + ")",
+		"文件数 | Files",
+		"容量 | Size",
+		"应删除文件数 | To delete",
+		"应删除容量 | Size to delete",
+		"已删除文件数 | Deleted",
+		"已删除容量 | Size deleted",
+		"删除失败文件数 | Failed",
+		"删除失败容量 | Size failed",
+	})
+	groupTable.SetAutoWrapText(false)
+	groupTable.SetAutoFormatHeaders(false)
+	groupTable.SetHeaderColor(
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+		tablewriter.Colors{tablewriter.Bold, tablewriter.FgCyanColor},
+	)
+	groupTable.SetColumnColor(
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiWhiteColor},
+		tablewriter.Colors{tablewriter.FgHiCyanColor},
+		tablewriter.Colors{tablewriter.FgHiRedColor},
+		tablewriter.Colors{tablewriter.FgHiRedColor},
+		tablewriter.Colors{tablewriter.FgGreenColor},
+		tablewriter.Colors{tablewriter.FgGreenColor},
+		tablewriter.Colors{tablewriter.FgRedColor},
+		tablewriter.Colors{tablewriter.FgRedColor},
+	)
+
+	for _, group := range r.Groups {
+		groupTable.Append([]string{
+			group.Group,
+			fmt.Sprintf("%d", group.TotalFiles),
+			opts.formatSize(group.TotalSize),
+			fmt.Sprintf("%d", group.FilesToDelete),
+			opts.formatSize(group.SizeToDelete),
+			fmt.Sprintf("%d", group.FilesDeleted),
+			opts.formatSize(group.SizeDeleted),
+			fmt.Sprintf("%d", group.FilesFailed),
+			opts.formatSize(group.SizeFailed),
+		})
+	}
+
+	groupTable.Render()
+}
+
+// truncateString 截断字符串，确保中日韩文字符占两个字节
+// truncateString truncates string, ensuring CJK characters count as two bytes
+func truncateString(s string, maxBytes int) string {
+	// 计算字符串的字节宽度
+	// Calculate string byte width
+	var totalWidth int
+	for _, r := range s {
+		var runeWidth int
+		if r >= 0x4E00 && r <= 0x9FFF || // CJK统一表意文字 | CJK Unified Ideographs
+			r >= 0x3040 && r <= 0x309F || // 平假名 | Hiragana
+			r >= 0x30A0 && r <= 0x30FF || // 片假名 | Katakana
+			r >= 0x3400 && r <= 0x4DBF || // CJK统一表意文字扩展A | CJK Unified Ideographs Extension A
+			r >= 0x20000 && r <= 0x2A6DF || // CJK统一表意文字扩展B | CJK Unified Ideographs Extension B
+			r >= 0x2A700 && r <= 0x2B73F || // CJK统一表意文字扩展C | CJK Unified Ideographs Extension C
+			r >= 0x2B740 && r <= 0x2B81F || // CJK统一表意文字扩展D | CJK Unified Ideographs Extension D
+			r >= 0x2B820 && r <= 0x2CEAF || // CJK统一表意文字扩展E | CJK Unified Ideographs Extension E
+			r >= 0xAC00 && r <= 0xD7AF || // 朝鲜文音节 | Hangul Syllables
+			r >= 0xF900 && r <= 0xFAFF || // CJK兼容表意文字 | CJK Compatibility Ideographs
+			r >= 0xFF00 && r <= 0xFFEF {
+			runeWidth = 2
+		} else {
+			runeWidth = 1
+		}
+		totalWidth += runeWidth
+	}
+
+	// 如果总宽度不超过最大宽度，则不需要截断
+	// If total width does not exceed max width, no need to truncate
+	if totalWidth <= maxBytes {
+		return s
+	}
+
+	// 需要截断
+	// Need to truncate
+	var currentBytes int
+	var truncated []rune
+	for _, r := range s {
+		var runeWidth int
+		if r >= 0x4E00 && r <= 0x9FFF || // CJK统一表意文字 | CJK Unified Ideographs
+			r >= 0x3040 && r <= 0x309F || // 平假名 | Hiragana
+			r >= 0x30A0 && r <= 0x30FF || // 片假名 | Katakana
+			r >= 0x3400 && r <= 0x4DBF || // CJK统一表意文字扩展A | CJK Unified Ideographs Extension A
+			r >= 0x20000 && r <= 0x2A6DF || // CJK统一表意文字扩展B | CJK Unified Ideographs Extension B
+			r >= 0x2A700 && r <= 0x2B73F || // CJK统一表意文字扩展C | CJK Unified Ideographs Extension C
+			r >= 0x2B740 && r <= 0x2B81F || // CJK统一表意文字扩展D | CJK Unified Ideographs Extension D
+			r >= 0x2B820 && r <= 0x2CEAF || // CJK统一表意文字扩展E | CJK Unified Ideographs Extension E
+			r >= 0xAC00 && r <= 0xD7AF || // 朝鲜文音节 | Hangul Syllables
+			r >= 0xF900 && r <= 0xFAFF || // CJK兼容表意文字 | CJK Compatibility Ideographs
+			r >= 0xFF00 && r <= 0xFFEF {
+			runeWidth = 2
+		} else {
+			runeWidth = 1
+		}
+
+		if currentBytes+runeWidth > maxBytes-3 { // 为省略号预留空间 | Reserve space for ellipsis
+			break
+		}
+
+		truncated = append(truncated, r)
+		currentBytes += runeWidth
+	}
+
+	return string(truncated) + "..."
+}
+
+// formatLastPart 格式化最新分段的时间，没有分段时显示 -
+// formatLastPart formats the time of the newest part, showing - when there are no parts
+func formatLastPart(file cleaner.FileInfo, loc *time.Location) string {
+	if file.LastPartTime == nil {
+		return "-"
+	}
+	return formatTime(*file.LastPartTime, loc)
+}
+
+// formatRule 格式化匹配的策略规则，没有匹配规则时显示 -
+// formatRule formats the matching policy rule, showing - when no rule matched
+func formatRule(file cleaner.FileInfo) string {
+	if file.Rule == "" {
+		return "-"
+	}
+	return file.Rule
+}
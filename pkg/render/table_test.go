@@ -0,0 +1,70 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// ansiEscape 匹配终端颜色控制序列
+// ansiEscape matches terminal color escape sequences
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestTableOptionalColumnsOrder(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastPart := start.Add(-2 * time.Hour)
+	result := &cleaner.Result{
+		StartTime: start,
+		Target:    cleaner.TargetVersions,
+		AgeBasis:  cleaner.AgeBasisLastPart,
+		Rules:     []string{"tmp"},
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", VersionID: "v1", Size: 100, ModTime: start.Add(-3 * time.Hour), LastPartTime: &lastPart, Rule: "tmp", ShouldDelete: true},
+		},
+	}
+	var buf bytes.Buffer
+	if err := outputTable(&buf, result, Options{Location: time.UTC}); err != nil {
+		t.Fatal(err)
+	}
+	out := ansiEscape.ReplaceAllString(buf.String(), "")
+
+	// 表头和行中的列顺序一致 | The header and the row have the columns in the same order
+	lines := strings.Split(out, "\n")
+	inOrder := func(line string, values ...string) bool {
+		pos := 0
+		for _, value := range values {
+			i := strings.Index(line[pos:], value)
+			if i < 0 {
+				return false
+			}
+			pos += i + len(value)
+		}
+		return true
+	}
+	var header, row string
+	for _, line := range lines {
+		if strings.Contains(line, "VERSION ID") {
+			header = line
+		}
+		if strings.Contains(line, "x.bin") {
+			row = line
+		}
+	}
+	if !inOrder(header, "BUCKET", "KEY", "VERSION ID", "SIZE", "MOD TIME", "LAST PART", "AGE", "STATUS", "RULE") {
+		t.Errorf("header columns out of order:\n%s", header)
+	}
+	if !inOrder(row, "a", "x.bin", "v1", "100 B", "2025-06-01 09:00:00", "2025-06-01 10:00:00", "2h 0m", "tmp") {
+		t.Errorf("row columns out of order:\n%s", row)
+	}
+}
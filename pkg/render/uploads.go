@@ -0,0 +1,209 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+// ReadUploadList 读取要中止的分段上传列表，格式为 CSV 或 JSON Lines，按第一个非空字符判断：
+//   - CSV：带表头时按列名读取 Bucket、Key、UploadId（以及可选的 Size、ModTime、ShouldDelete、DeleteSuccess），因此可以直接读取 --fmt=csv 的输出；
+//     没有表头时每行为 bucket,key,uploadId
+//   - JSON Lines：每行一个对象，包含 bucket、key、upload_id 或 uploadId（以及可选的 size、mod_time、should_delete、delete_success）
+//
+// 列表按给出的内容读取；staleOnly 为 true 时跳过 ShouldDelete 为 false 的行。DeleteSuccess 为 true 的行已经中止过，
+// 总是被跳过，重复的上传只保留一个。
+//
+// ReadUploadList reads the list of multipart uploads to abort in CSV or JSON Lines, decided by the first non-blank character:
+//   - CSV: with a header, Bucket, Key, UploadId (and optionally Size, ModTime, ShouldDelete and DeleteSuccess) are read by column name,
+//     so the output of --fmt=csv can be read directly; without a header every row is bucket,key,uploadId
+//   - JSON Lines: one object per line with bucket, key, upload_id or uploadId (and optionally size, mod_time, should_delete and delete_success)
+//
+// The list is read as given; when staleOnly is true rows whose ShouldDelete is false are skipped. Rows whose
+// DeleteSuccess is true were already aborted and are always skipped, and duplicate uploads are kept once
+func ReadUploadList(r io.Reader, staleOnly bool) ([]cleaner.FileInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取上传列表: %v\nFailed to read upload list: %v", err, err)
+	}
+	// 去掉 Excel 等工具写入的 UTF-8 BOM | Strip the UTF-8 BOM written by tools such as Excel
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, fmt.Errorf("上传列表为空\nThe upload list is empty")
+	}
+
+	var uploads []cleaner.FileInfo
+	if trimmed[0] == '{' {
+		uploads, err = readUploadJSONLines(bytes.NewReader(data), staleOnly)
+	} else {
+		uploads, err = readUploadCSV(bytes.NewReader(data), staleOnly)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(uploads) == 0 {
+		return nil, fmt.Errorf("上传列表中没有要中止的上传\nThe upload list has no uploads to abort")
+	}
+	return dedupeUploads(uploads), nil
+}
+
+// uploadListLine JSON Lines 上传列表中的一行
+// uploadListLine is one line of a JSON Lines upload list
+type uploadListLine struct {
+	Bucket        string    `json:"bucket"`
+	Key           string    `json:"key"`
+	UploadID      string    `json:"upload_id"`
+	UploadIDAlt   string    `json:"uploadId"`
+	Size          int64     `json:"size"`
+	ModTime       time.Time `json:"mod_time"`
+	ShouldDelete  *bool     `json:"should_delete"`
+	DeleteSuccess *bool     `json:"delete_success"`
+}
+
+// readUploadJSONLines 读取 JSON Lines 格式的上传列表
+// readUploadJSONLines reads an upload list in JSON Lines format
+func readUploadJSONLines(r io.Reader, staleOnly bool) ([]cleaner.FileInfo, error) {
+	var uploads []cleaner.FileInfo
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var entry uploadListLine
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("第 %d 行不是有效的JSON: %v\nLine %d is not valid JSON: %v", lineNo, err, lineNo, err)
+		}
+		if staleOnly && entry.ShouldDelete != nil && !*entry.ShouldDelete {
+			continue
+		}
+		if entry.DeleteSuccess != nil && *entry.DeleteSuccess {
+			continue
+		}
+		if entry.UploadID == "" {
+			entry.UploadID = entry.UploadIDAlt
+		}
+		upload, err := newListedUpload(lineNo, entry.Bucket, entry.Key, entry.UploadID, entry.Size, entry.ModTime)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("无法读取上传列表: %v\nFailed to read upload list: %v", err, err)
+	}
+	return uploads, nil
+}
+
+// readUploadCSV 读取 CSV 格式的上传列表
+// readUploadCSV reads an upload list in CSV format
+func readUploadCSV(r io.Reader, staleOnly bool) ([]cleaner.FileInfo, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("无法解析CSV上传列表: %v\nFailed to parse CSV upload list: %v", err, err)
+	}
+
+	// 第一行包含 Bucket、Key 和 UploadId 列名时视为表头
+	// The first row is a header when it has the Bucket, Key and UploadId column names
+	columns := map[string]int{"bucket": 0, "key": 1, "uploadid": 2}
+	start := 0
+	header := map[string]int{}
+	for i, name := range records[0] {
+		header[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))] = i
+	}
+	_, hasBucket := header["bucket"]
+	_, hasKey := header["key"]
+	_, hasUploadID := header["uploadid"]
+	if hasBucket && hasKey && hasUploadID {
+		columns = header
+		start = 1
+	} else if hasBucket || hasKey {
+		return nil, fmt.Errorf("CSV 表头缺少 UploadId 列，--fmt=csv 的输出需要使用 --target uploads\nThe CSV header has no UploadId column, --fmt=csv output must come from --target uploads")
+	}
+
+	var uploads []cleaner.FileInfo
+	for i, record := range records[start:] {
+		lineNo := start + i + 1
+		field := func(name string) string {
+			if index, ok := columns[name]; ok && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		if len(record) == 1 && field("bucket") == "" {
+			continue
+		}
+		if staleOnly && field("shoulddelete") == "false" {
+			continue
+		}
+		if field("deletesuccess") == "true" {
+			continue
+		}
+
+		var size int64
+		if text := field("size"); text != "" {
+			if size, err = strconv.ParseInt(text, 10, 64); err != nil {
+				return nil, fmt.Errorf("第 %d 行的大小 '%s' 无效\nInvalid size '%s' on line %d", lineNo, text, text, lineNo)
+			}
+		}
+		var modTime time.Time
+		if text := field("modtime"); text != "" {
+			if modTime, err = time.Parse(time.RFC3339, text); err != nil {
+				return nil, fmt.Errorf("第 %d 行的修改时间 '%s' 无效\nInvalid mod time '%s' on line %d", lineNo, text, text, lineNo)
+			}
+		}
+		upload, err := newListedUpload(lineNo, field("bucket"), field("key"), field("uploadid"), size, modTime)
+		if err != nil {
+			return nil, err
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+// newListedUpload 检查列表中的一行并创建上传
+// newListedUpload checks one row of the list and creates the upload
+func newListedUpload(lineNo int, bucket, key, uploadID string, size int64, modTime time.Time) (cleaner.FileInfo, error) {
+	if bucket == "" || key == "" || uploadID == "" {
+		return cleaner.FileInfo{}, fmt.Errorf("第 %d 行缺少 bucket、key 或 uploadId\nLine %d is missing bucket, key or uploadId", lineNo, lineNo)
+	}
+	return cleaner.FileInfo{Bucket: bucket, Key: key, UploadID: uploadID, Size: size, ModTime: modTime, Type: cleaner.FileTypeUpload}, nil
+}
+
+// dedupeUploads 去掉重复的上传，保持顺序
+// dedupeUploads removes duplicate uploads, keeping the order
+func dedupeUploads(uploads []cleaner.FileInfo) []cleaner.FileInfo {
+	type uploadKey struct{ bucket, key, uploadID string }
+	seen := map[uploadKey]bool{}
+	result := uploads[:0]
+	for _, upload := range uploads {
+		k := uploadKey{upload.Bucket, upload.Key, upload.UploadID}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		result = append(result, upload)
+	}
+	return result
+}
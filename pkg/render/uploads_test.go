@@ -0,0 +1,151 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package render
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bitiful/s4-cleaner/pkg/cleaner"
+)
+
+func TestReadUploadList(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		staleOnly bool
+		want      []string
+	}{
+		{
+			name: "CSV without header",
+			in:   "a,x.bin,u1\nb, y.bin ,u2\n\na,x.bin,u1\n",
+			want: []string{"a/x.bin/u1", "b/y.bin/u2"},
+		},
+		{
+			name: "CSV with header in any order",
+			in:   "UploadId,Key,Bucket,ShouldDelete\nu1,x.bin,a,true\nu2,y.bin,b,false\nu3,z.bin,a,\n",
+			want: []string{"a/x.bin/u1", "b/y.bin/u2", "a/z.bin/u3"},
+		},
+		{
+			name:      "CSV with only stale rows",
+			in:        "UploadId,Key,Bucket,ShouldDelete\nu1,x.bin,a,true\nu2,y.bin,b,false\nu3,z.bin,a,\n",
+			staleOnly: true,
+			want:      []string{"a/x.bin/u1", "a/z.bin/u3"},
+		},
+		{
+			name: "CSV skips aborted rows",
+			in:   "Bucket,Key,UploadId,DeleteSuccess\na,x.bin,u1,true\na,y.bin,u2,false\na,z.bin,u3,skipped\na,w.bin,u4,not_executed\n",
+			want: []string{"a/y.bin/u2", "a/z.bin/u3", "a/w.bin/u4"},
+		},
+		{
+			name: "CSV with UTF-8 BOM",
+			in:   "\ufeffbucket,key,upload_id\na,x.bin,u1\n",
+			want: []string{"a/x.bin/u1"},
+		},
+		{
+			name: "JSON Lines",
+			in: `{"bucket":"a","key":"x.bin","upload_id":"u1","should_delete":true}
+
+{"bucket":"b","key":"y.bin","uploadId":"u2"}
+{"bucket":"c","key":"z.bin","upload_id":"u3","should_delete":false}
+{"bucket":"a","key":"x.bin","upload_id":"u1"}
+{"bucket":"d","key":"w.bin","upload_id":"u4","delete_success":true}
+{"bucket":"d","key":"v.bin","upload_id":"u5","delete_success":false}
+`,
+			want: []string{"a/x.bin/u1", "b/y.bin/u2", "c/z.bin/u3", "d/v.bin/u5"},
+		},
+		{
+			name: "JSON Lines with only stale rows",
+			in: `{"bucket":"a","key":"x.bin","upload_id":"u1","should_delete":true}
+{"bucket":"b","key":"y.bin","uploadId":"u2"}
+{"bucket":"c","key":"z.bin","upload_id":"u3","should_delete":false}
+`,
+			staleOnly: true,
+			want:      []string{"a/x.bin/u1", "b/y.bin/u2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uploads, err := ReadUploadList(strings.NewReader(tt.in), tt.staleOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, upload := range uploads {
+				if upload.Type != cleaner.FileTypeUpload {
+					t.Errorf("upload %s has type %q", upload.Key, upload.Type)
+				}
+				got = append(got, upload.Bucket+"/"+upload.Key+"/"+upload.UploadID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("uploads = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadUploadListFromCSVOutput(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	result := &cleaner.Result{
+		StartTime: start,
+		Target:    cleaner.TargetUploads,
+		Files: []cleaner.FileInfo{
+			{Bucket: "a", Key: "x.bin", UploadID: "u1", Size: 100, ModTime: start.Add(-48 * time.Hour), Type: cleaner.FileTypeUpload, ShouldDelete: true},
+			{Bucket: "a", Key: "y.bin", UploadID: "u2", Size: 200, ModTime: start.Add(-time.Hour), Type: cleaner.FileTypeUpload},
+		},
+	}
+	var buf bytes.Buffer
+	if err := outputCSV(&buf, result, Options{Location: time.UTC}); err != nil {
+		t.Fatal(err)
+	}
+
+	uploads, err := ReadUploadList(bytes.NewReader(buf.Bytes()), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploads) != 1 {
+		t.Fatalf("uploads = %+v, want only the stale upload u1", uploads)
+	}
+	if got := uploads[0]; got.UploadID != "u1" || got.Size != 100 || !got.ModTime.Equal(result.Files[0].ModTime) {
+		t.Errorf("upload = %+v, want u1 with its size and mod time", got)
+	}
+
+	// 不加 staleOnly 时读取所有行 | Every row is read without staleOnly
+	if uploads, err := ReadUploadList(bytes.NewReader(buf.Bytes()), false); err != nil || len(uploads) != 2 {
+		t.Errorf("ReadUploadList() = %+v, %v, want both uploads", uploads, err)
+	}
+}
+
+func TestReadUploadListErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", " \n", "empty"},
+		{"nothing to abort", "bucket,key,uploadId,deleteSuccess\na,x,u1,true\n", "no uploads to abort"},
+		{"header without UploadId", "Bucket,Key,Size\na,x,1\n", "--target uploads"},
+		{"missing upload ID", "a,x.bin\n", "Line 1 is missing"},
+		{"invalid size", "bucket,key,uploadId,size\na,x,u1,big\n", "Invalid size 'big' on line 2"},
+		{"invalid mod time", "bucket,key,uploadId,modTime\na,x,u1,yesterday\n", "Invalid mod time 'yesterday' on line 2"},
+		{"invalid CSV", "a,\"x,u1\n", "CSV"},
+		{"invalid JSON line", "{\"bucket\":\"a\",\"key\":\"x\",\"upload_id\":\"u1\"}\n{\"bucket\":\n", "Line 2 is not valid JSON"},
+		{"JSON line missing key", `{"bucket":"a","upload_id":"u1"}`, "Line 1 is missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadUploadList(strings.NewReader(tt.in), false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadUploadList() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
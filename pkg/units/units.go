@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package units
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDuration 解析时间字符串为时长，如 '7d' 或 '72h'
+// ParseDuration parses a time string such as '7d' or '72h' to a duration
+func ParseDuration(s string) (time.Duration, error) {
+	value, unit, err := parseTimeSpec(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if unit == "d" {
+		return time.Duration(value) * 24 * time.Hour, nil
+	}
+	return time.Duration(value) * time.Hour, nil
+}
+
+// ParseDays 返回时间字符串对应的天数，不足一天的部分按一天计算
+// ParseDays returns the number of days of a time string, rounding partial days up
+func ParseDays(s string) (int, error) {
+	value, unit, err := parseTimeSpec(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if unit == "h" {
+		value = (value + 23) / 24
+	}
+	return value, nil
+}
+
+// parseTimeSpec 解析时间字符串，返回数值和单位
+// parseTimeSpec parses time string and returns its value and unit
+func parseTimeSpec(s string) (int, string, error) {
+	// 正则表达式匹配时间格式，如 7d, 72h
+	// Regular expression to match time format, e.g. 7d, 72h
+	re := regexp.MustCompile(`^(\d+)([dh])$`)
+	matches := re.FindStringSubmatch(s)
+
+	if len(matches) != 3 {
+		return 0, "", fmt.Errorf("无效的时间格式 '%s'，有效格式为: 数字+单位，如 '7d'（7天）或 '72h'（72小时）\nInvalid time format '%s', valid format is: number+unit, e.g. '7d' (7 days) or '72h' (72 hours)", s, s)
+	}
+
+	value, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, "", fmt.Errorf("无效的时间值 '%s'\nInvalid time value '%s'", matches[1], matches[1])
+	}
+
+	return value, matches[2], nil
+}
+
+// sizeUnits 容量单位及其字节数
+// sizeUnits maps size units to their number of bytes
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"TB":  1000 * 1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+	"TIB": 1 << 40,
+}
+
+// ParseSize 解析容量字符串，如 '1024'、'500MB' 或 '10GiB'
+// ParseSize parses a size string, e.g. '1024', '500MB' or '10GiB'
+func ParseSize(s string) (int64, error) {
+	re := regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([a-zA-Z]*)$`)
+	matches := re.FindStringSubmatch(strings.TrimSpace(s))
+
+	if len(matches) != 3 {
+		return 0, fmt.Errorf("无效的容量 '%s'，有效格式为: 数字+单位，如 '500MB' 或 '10GiB'\nInvalid size '%s', valid format is: number+unit, e.g. '500MB' or '10GiB'", s, s)
+	}
+
+	unit, ok := sizeUnits[strings.ToUpper(matches[2])]
+	if !ok {
+		return 0, fmt.Errorf("无效的容量单位 '%s'，有效单位为: B, KB, MB, GB, TB, KiB, MiB, GiB, TiB\nInvalid size unit '%s', valid units are: B, KB, MB, GB, TB, KiB, MiB, GiB, TiB", matches[2], matches[2])
+	}
+
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的容量值 '%s'\nInvalid size value '%s'", matches[1], matches[1])
+	}
+
+	return int64(value * float64(unit)), nil
+}
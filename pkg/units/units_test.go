@@ -0,0 +1,77 @@
+/*
+ * Copyright (c) 2025 缤纷云S4 (Bitiful S4)
+ *
+ * 缤纷云S3临时文件清理工具
+ * Bitiful S4 S3 Temporary File Cleaner
+ */
+
+package units
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"7d", 7 * 24 * time.Hour, false},
+		{"72h", 72 * time.Hour, false},
+		{"0h", 0, false},
+		{"30m", 0, true},
+		{"1.5d", 0, true},
+		{"d", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v, want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"7d", 7},
+		{"24h", 1},
+		{"25h", 2},
+		{"1h", 1},
+		{"0h", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseDays(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDays(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1024", 1024, false},
+		{"500MB", 500 * 1000 * 1000, false},
+		{"10GiB", 10 << 30, false},
+		{"1.5 KiB", 1536, false},
+		{"2tb", 2 * 1000 * 1000 * 1000 * 1000, false},
+		{"10XB", 0, true},
+		{"-1", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}